@@ -2,6 +2,7 @@ package artnet
 
 import (
 	"fmt"
+	"strconv"
 )
 
 // Address is an Art-Net v4 DMX universe address.
@@ -32,3 +33,21 @@ func (a Address) Universe() uint8 {
 func (a Address) String() string {
 	return fmt.Sprintf("%d:%d.%d", a.Net(), a.SubNet(), a.Universe())
 }
+
+// ParseAddress parses an address written as "net:subnet.universe", or as a
+// single 15-bit port-address number.
+func ParseAddress(s string) (Address, error) {
+	var net, subNet, universe uint8
+	if n, err := fmt.Sscanf(s, "%d:%d.%d", &net, &subNet, &universe); err == nil && n == 3 {
+		if net > 0x7F || subNet > 0xF || universe > 0xF {
+			return 0, fmt.Errorf("address %q is out of range", s)
+		}
+		return NewAddress(net, subNet, universe), nil
+	}
+
+	v, err := strconv.ParseUint(s, 0, 15)
+	if err != nil {
+		return 0, fmt.Errorf("invalid address %q", s)
+	}
+	return Address(v), nil
+}
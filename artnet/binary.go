@@ -0,0 +1,114 @@
+package artnet
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+
+	"lyra.codes/blinken/artnet/wire"
+)
+
+// Appender is implemented by packets which can append their encoding to a
+// buffer, so that hot paths can reuse one buffer for every packet they send.
+type Appender interface {
+	AppendBinary(dst []byte) ([]byte, error)
+}
+
+func (h Header) appendBinary(dst []byte) []byte {
+	dst = append(dst, Magic...)
+	return appendUint16(dst, uint16(h.Operation), binary.LittleEndian)
+}
+
+func (h *Header) unmarshalBinary(data []byte) error {
+	if len(data) < HeaderLength {
+		return &wire.FieldError{Field: "OpCode", Err: io.ErrUnexpectedEOF}
+	}
+	if !bytes.Equal(Magic, data[:len(Magic)]) {
+		return &wire.FieldError{Field: "ID", Err: fmt.Errorf("received invalid magic %q", data[:len(Magic)])}
+	}
+
+	h.Operation = Operation(binary.LittleEndian.Uint16(data[len(Magic):]))
+	return nil
+}
+
+func appendUint16(dst []byte, v uint16, ord binary.ByteOrder) []byte {
+	dst = append(dst, 0, 0)
+	ord.PutUint16(dst[len(dst)-2:], v)
+	return dst
+}
+
+// appendString appends a NUL-padded string in a field of the given size.
+func appendString(dst []byte, name, v string, size int) ([]byte, error) {
+	if len(v)+1 > size {
+		return dst, &wire.FieldError{Field: name, Err: fmt.Errorf("string is longer than capacity %d", size)}
+	}
+
+	dst = append(dst, v...)
+	for i := len(v); i < size; i++ {
+		dst = append(dst, 0)
+	}
+	return dst, nil
+}
+
+// appendIPv4 appends a 4-byte IPv4 address.
+func appendIPv4(dst []byte, name string, ip net.IP) ([]byte, error) {
+	v4 := ip.To4()
+	if v4 == nil {
+		return dst, &wire.FieldError{Field: name, Err: fmt.Errorf("IP %q is not an IPv4 address", ip)}
+	}
+
+	return append(dst, v4...), nil
+}
+
+// unmarshalString returns the NUL-terminated string in b, reusing old if it
+// hasn't changed. In lenient mode, a string filling b without a NUL is
+// accepted.
+func unmarshalString(name string, b []byte, old string, mode wire.Mode) (string, error) {
+	end := bytes.IndexByte(b, 0)
+	if end < 0 && mode == wire.Strict {
+		return old, &wire.FieldError{Field: name, Err: errors.New("terminating NUL not found")}
+	}
+	if end < 0 {
+		end = len(b)
+	}
+
+	if string(b[:end]) == old {
+		return old, nil
+	}
+	return string(b[:end]), nil
+}
+
+// binaryUnmarshaler is implemented by packets which can decode themselves
+// from a buffer in either parsing mode.
+type binaryUnmarshaler interface {
+	unmarshalBinary(data []byte, mode wire.Mode) error
+}
+
+// fixedLength checks the length of a packet which should be n bytes long. In
+// lenient mode, a short packet is copied into buf, padded with zeros, and a
+// long one is cut short.
+func fixedLength(name string, data []byte, n int, mode wire.Mode, buf []byte) ([]byte, error) {
+	switch {
+	case len(data) == n:
+		return data, nil
+	case mode == wire.Strict && len(data) < n:
+		return nil, &wire.FieldError{Field: name, Err: io.ErrUnexpectedEOF}
+	case mode == wire.Strict:
+		return nil, trailingError(name, len(data)-n)
+	case len(data) < n:
+		copy(buf[:n], data)
+		for i := len(data); i < n; i++ {
+			buf[i] = 0
+		}
+		return buf[:n], nil
+	default:
+		return data[:n], nil
+	}
+}
+
+func trailingError(name string, n int) error {
+	return &wire.FieldError{Field: name, Err: fmt.Errorf("%d unexpected bytes after the end of the message", n)}
+}
@@ -0,0 +1,191 @@
+package artnet
+
+import (
+	"bytes"
+	"net"
+	"reflect"
+	"testing"
+
+	"lyra.codes/blinken/dmx"
+)
+
+func testUniverse() dmx.Universe {
+	u := make(dmx.Universe, 512)
+	for i := range u {
+		u[i] = dmx.Channel(i)
+	}
+	return u
+}
+
+func testPollReply() *PollReply {
+	return &PollReply{
+		Header:           pollReplyHeader,
+		Node:             net.UDPAddr{IP: net.IPv4(2, 0, 0, 10).To4(), Port: Port},
+		FirmwareVersion:  0x0102,
+		NetSwitch:        1,
+		SubSwitch:        2,
+		OEM:              0x2828,
+		Status1:          0xd2,
+		ESTAManufacturer: 0x7a70,
+		ShortName:        "blinken",
+		LongName:         "blinken test node",
+		NodeReport:       "#0001 [0042] Power On Tests successful",
+		PortCount:        2,
+		PortTypes:        []PortType{0x80, 0x80, 0, 0},
+		PortInputs:       []PortInput{PortInputDisabled, 0, 0, 0},
+		PortOutputs:      []PortOutput{PortOutputTransmitting, PortOutputMerging, 0, 0},
+		InputUniverses:   []uint8{0, 1, 0, 0},
+		OutputUniverses:  []uint8{3, 4, 0, 0},
+		Style:            StyleNode,
+		MAC:              net.HardwareAddr{0x02, 0x00, 0x00, 0x00, 0x00, 0x0a},
+		BindIP:           net.IPv4(2, 0, 0, 10).To4(),
+		BindIndex:        1,
+		Status2:          0x0e,
+	}
+}
+
+type binaryPacket interface {
+	Packet
+	Appender
+	UnmarshalBinary(data []byte) error
+}
+
+func TestAppendBinary(t *testing.T) {
+	tests := []struct {
+		packet binaryPacket
+		empty  binaryPacket
+	}{
+		{NewDMX(0x0102, 7, testUniverse()), &DMX{}},
+		{NewDMX(0x0010, 0, dmx.Universe{1, 2}), &DMX{}},
+		{NewPoll(PollPush(), PollDiagnostics(DPHigh, true)), &Poll{}},
+		{NewSync(), &Sync{}},
+		{testPollReply(), &PollReply{}},
+	}
+
+	for _, tt := range tests {
+		buf := bytes.Buffer{}
+		if err := tt.packet.Write(&buf); err != nil {
+			t.Fatalf("%T.Write: %v", tt.packet, err)
+		}
+
+		prefix := []byte("prefix")
+		b, err := tt.packet.AppendBinary(prefix)
+		if err != nil {
+			t.Fatalf("%T.AppendBinary: %v", tt.packet, err)
+		}
+		if !bytes.Equal(b[:len(prefix)], prefix) || !bytes.Equal(b[len(prefix):], buf.Bytes()) {
+			t.Errorf("%T.AppendBinary = %x, want %x", tt.packet, b[len(prefix):], buf.Bytes())
+		}
+
+		if err := tt.empty.UnmarshalBinary(buf.Bytes()); err != nil {
+			t.Fatalf("%T.UnmarshalBinary: %v", tt.empty, err)
+		}
+		if !reflect.DeepEqual(tt.empty, tt.packet) {
+			t.Errorf("%T.UnmarshalBinary = %+v, want %+v", tt.empty, tt.empty, tt.packet)
+		}
+	}
+}
+
+func TestUnmarshalBinaryShort(t *testing.T) {
+	b, err := NewDMX(1, 1, testUniverse()).MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, n := range []int{0, HeaderLength, dmxHeaderLength, len(b) - 1} {
+		if err := (&DMX{}).UnmarshalBinary(b[:n]); err == nil {
+			t.Errorf("UnmarshalBinary accepted %d of %d bytes", n, len(b))
+		}
+	}
+}
+
+func TestDMXZeroAllocations(t *testing.T) {
+	p := NewDMX(0x0102, 1, testUniverse())
+	q := &DMX{}
+	buf := make([]byte, 0, 1024)
+
+	allocs := testing.AllocsPerRun(100, func() {
+		p.Sequence = NextSequence(p.Sequence)
+		buf, _ = p.AppendBinary(buf[:0])
+		_ = q.UnmarshalBinary(buf)
+	})
+	if allocs != 0 {
+		t.Errorf("DMX round trip made %v allocations, want 0", allocs)
+	}
+}
+
+func BenchmarkDMXWrite(b *testing.B) {
+	p := NewDMX(0x0102, 1, testUniverse())
+	b.ReportAllocs()
+
+	for i := 0; i < b.N; i++ {
+		buf := bytes.Buffer{}
+		if err := p.Write(&buf); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkDMXAppendBinary(b *testing.B) {
+	p := NewDMX(0x0102, 1, testUniverse())
+	buf := make([]byte, 0, 1024)
+	b.ReportAllocs()
+	b.SetBytes(int64(dmxHeaderLength + len(p.Data)))
+
+	for i := 0; i < b.N; i++ {
+		var err error
+		if buf, err = p.AppendBinary(buf[:0]); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkDMXRead(b *testing.B) {
+	data, _ := NewDMX(0x0102, 1, testUniverse()).MarshalBinary()
+	b.ReportAllocs()
+
+	for i := 0; i < b.N; i++ {
+		p := &DMX{}
+		if err := p.Read(bytes.NewBuffer(data)); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkDMXUnmarshalBinary(b *testing.B) {
+	data, _ := NewDMX(0x0102, 1, testUniverse()).MarshalBinary()
+	p := &DMX{}
+	b.ReportAllocs()
+	b.SetBytes(int64(len(data)))
+
+	for i := 0; i < b.N; i++ {
+		if err := p.UnmarshalBinary(data); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkPollReplyAppendBinary(b *testing.B) {
+	p := testPollReply()
+	buf := make([]byte, 0, 1024)
+	b.ReportAllocs()
+
+	for i := 0; i < b.N; i++ {
+		var err error
+		if buf, err = p.AppendBinary(buf[:0]); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkPollReplyUnmarshalBinary(b *testing.B) {
+	data, _ := testPollReply().MarshalBinary()
+	p := &PollReply{}
+	b.ReportAllocs()
+
+	for i := 0; i < b.N; i++ {
+		if err := p.UnmarshalBinary(data); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,102 @@
+package artnet
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"lyra.codes/blinken/artnet/wire"
+)
+
+var (
+	commandHeader = Header{Operation: OpCommand}
+)
+
+// ESTAAll is the ESTA manufacturer code of commands every node understands.
+const ESTAAll uint16 = 0xFFFF
+
+// Command is the contents of an OpCommand message, which carries text
+// commands for nodes. Its Text holds one or more directives, each written
+// as "Name=Value&".
+type Command struct {
+	Header
+	Version          Version
+	ESTAManufacturer uint16
+	Length           uint16
+	Text             string `wire:"size=Length,max=512"`
+}
+
+// NewCommand creates a new Command operation, for nodes made by the given
+// ESTA manufacturer.
+func NewCommand(esta uint16, directives ...Directive) *Command {
+	text := strings.Builder{}
+	for _, d := range directives {
+		text.WriteString(d.String())
+	}
+
+	return &Command{
+		Header:           commandHeader,
+		Version:          Version14,
+		ESTAManufacturer: esta,
+		Text:             text.String(),
+	}
+}
+
+// Command creates a manufacturer-specific Command for the node. Standard
+// directives like SwoutText should be sent with NewCommand(ESTAAll, ...).
+func (n *Node) Command(directives ...Directive) *Command {
+	return NewCommand(n.ESTAManufacturer, directives...)
+}
+
+// Directives parses the text of a Command.
+func (p *Command) Directives() []Directive {
+	var directives []Directive
+
+	for _, part := range strings.Split(p.Text, "&") {
+		if part == "" {
+			continue
+		}
+
+		d := Directive{Name: part}
+		if eq := strings.IndexByte(part, '='); eq >= 0 {
+			d.Name, d.Value = part[:eq], part[eq+1:]
+		}
+		directives = append(directives, d)
+	}
+
+	return directives
+}
+
+func (p *Command) Read(r wire.Reader) error {
+	return wire.Decode(r, p)
+}
+
+func (p *Command) Write(w io.Writer) error {
+	return wire.Encode(w, p)
+}
+
+// Directive is a single command within a Command.
+type Directive struct {
+	Name  string
+	Value string
+}
+
+// Standard directive names understood by all nodes.
+const (
+	DirectiveSwoutText = "SwoutText"
+	DirectiveSwinText  = "SwinText"
+)
+
+// SwoutText renames the "Playback" label nodes show for output ports.
+func SwoutText(text string) Directive {
+	return Directive{Name: DirectiveSwoutText, Value: text}
+}
+
+// SwinText renames the "Record" label nodes show for input ports.
+func SwinText(text string) Directive {
+	return Directive{Name: DirectiveSwinText, Value: text}
+}
+
+func (d Directive) String() string {
+	return fmt.Sprintf("%s=%s&", d.Name, d.Value)
+}
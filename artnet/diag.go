@@ -0,0 +1,43 @@
+package artnet
+
+import (
+	"io"
+
+	"lyra.codes/blinken/artnet/wire"
+)
+
+var (
+	diagDataHeader = Header{Operation: OpDiagData}
+)
+
+// DiagData is the contents of an OpDiagData message, a diagnostic message
+// sent by a node to the controllers which asked for them with PollDiagnostics.
+type DiagData struct {
+	Header
+	Version     Version
+	_           [1]byte `wire:"skip"`
+	Priority    DiagnosticPriority
+	LogicalPort uint8
+	_           [1]byte `wire:"skip"`
+	Length      uint16
+	Text        string `wire:"size=Length,max=512"`
+}
+
+// NewDiagData creates a new DiagData operation.
+func NewDiagData(priority DiagnosticPriority, port uint8, text string) *DiagData {
+	return &DiagData{
+		Header:      diagDataHeader,
+		Version:     Version14,
+		Priority:    priority,
+		LogicalPort: port,
+		Text:        text,
+	}
+}
+
+func (p *DiagData) Read(r wire.Reader) error {
+	return wire.Decode(r, p)
+}
+
+func (p *DiagData) Write(w io.Writer) error {
+	return wire.Encode(w, p)
+}
@@ -2,6 +2,7 @@ package artnet
 
 import (
 	"encoding/binary"
+	"fmt"
 	"io"
 
 	"lyra.codes/blinken/artnet/wire"
@@ -18,9 +19,9 @@ type DMX struct {
 	Version  Version
 	Sequence uint8
 	Input    uint8
-	Address  Address
+	Address  Address `wire:"le"`
 	Length   uint16
-	Data     dmx.Universe
+	Data     dmx.Universe `wire:"size=Length,max=512"`
 }
 
 // NewDMX creates a new DMX operation.
@@ -36,38 +37,126 @@ func NewDMX(dest Address, seq uint8, channels dmx.Universe) *DMX {
 	}
 }
 
-func (p *DMX) Read(r wire.Reader) error {
-	p.Header.Read(r)
-
-	parser := wire.Parse(r)
-	p.Version = Version(parser.Int16("Version", binary.BigEndian))
-	p.Sequence = parser.Int8("Sequence")
-	p.Input = parser.Int8("Sequence")
-	p.Address = Address(parser.Int16("Address", binary.LittleEndian))
-	p.Length = parser.Int16("Length", binary.BigEndian)
-	if parser.Err() != nil {
-		return parser.Err()
+// InSequence reports whether a DMX packet with sequence number next should be
+// accepted after one with sequence number last. A sequence number of zero
+// means the sender doesn't use sequencing, so such packets are always accepted.
+func InSequence(last, next uint8) bool {
+	if last == 0 || next == 0 {
+		return true
 	}
 
-	p.Data = make(dmx.Universe, int(p.Length))
-	_, err := r.Read([]byte(p.Data))
-	return err
+	diff := int8(next - last)
+	return diff > 0 || diff <= -20
+}
+
+// NextSequence returns the sequence number to send after seq, skipping zero.
+func NextSequence(seq uint8) uint8 {
+	seq++
+	if seq == 0 {
+		seq = 1
+	}
+	return seq
+}
+
+func (p *DMX) Read(r wire.Reader) error {
+	if err := wire.Decode(r, p); err != nil {
+		return err
+	}
+	return checkDMXLength(int(p.Length))
 }
 
 func (p *DMX) Write(w io.Writer) error {
-	p.Header.Write(w)
-
-	err := wire.Build(w).
-		Int16("Version", uint16(p.Version), binary.BigEndian).
-		Int8("Sequence", uint8(p.Sequence)).
-		Int8("Input", uint8(p.Input)).
-		Int16("Address", uint16(p.Address), binary.LittleEndian).
-		Int16("Length", p.Length, binary.BigEndian).
-		Err()
+	return wire.Encode(w, p)
+}
+
+// dmxHeaderLength is the length of a DMX message before its data.
+const dmxHeaderLength = HeaderLength + 8
+
+// maxDMXData is the number of channels in a DMX universe.
+const maxDMXData = 512
+
+// checkDMXLength checks that the Length of a strictly read DMX message is
+// even and from 2 to 512, as the spec requires.
+func checkDMXLength(n int) error {
+	if n < 2 || n > maxDMXData || n%2 != 0 {
+		return &wire.FieldError{Field: "Length", Err: fmt.Errorf("length %d is not even from 2 to %d", n, maxDMXData)}
+	}
+	return nil
+}
+
+// MarshalBinary returns the encoded message.
+func (p *DMX) MarshalBinary() ([]byte, error) {
+	return p.AppendBinary(nil)
+}
+
+// AppendBinary appends the encoded message to dst, setting its Length from
+// its Data.
+func (p *DMX) AppendBinary(dst []byte) ([]byte, error) {
+	if len(p.Data) > maxDMXData {
+		return dst, &wire.FieldError{Field: "Data", Err: fmt.Errorf("%d elements is more than %d", len(p.Data), maxDMXData)}
+	}
+	p.Length = uint16(len(p.Data))
+
+	dst = p.Header.appendBinary(dst)
+	dst = appendUint16(dst, uint16(p.Version), binary.BigEndian)
+	dst = append(dst, p.Sequence, p.Input)
+	dst = appendUint16(dst, uint16(p.Address), binary.LittleEndian)
+	dst = appendUint16(dst, p.Length, binary.BigEndian)
+	return append(dst, p.Data...), nil
+}
+
+// UnmarshalBinary strictly decodes a message, reusing the memory of p.Data.
+// Strict decoding requires an even Length from 2 to 512.
+func (p *DMX) UnmarshalBinary(data []byte) error {
+	return p.unmarshalBinary(data, wire.Strict)
+}
+
+func (p *DMX) unmarshalBinary(data []byte, mode wire.Mode) error {
+	if err := p.Header.unmarshalBinary(data); err != nil {
+		return err
+	}
+
+	head, body := data, []byte(nil)
+	if len(data) > dmxHeaderLength {
+		head, body = data[:dmxHeaderLength], data[dmxHeaderLength:]
+	}
+
+	var buf [dmxHeaderLength]byte
+	head, err := fixedLength("Length", head, dmxHeaderLength, mode, buf[:])
 	if err != nil {
 		return err
 	}
 
-	_, err = w.Write([]byte(p.Data))
-	return err
+	p.Version = Version(binary.BigEndian.Uint16(head[10:]))
+	p.Sequence = head[12]
+	p.Input = head[13]
+	p.Address = Address(binary.LittleEndian.Uint16(head[14:]))
+	p.Length = binary.BigEndian.Uint16(head[16:])
+
+	n := int(p.Length)
+	if n > maxDMXData {
+		return &wire.FieldError{Field: "Data", Err: fmt.Errorf("size %d is more than %d", n, maxDMXData)}
+	}
+
+	if mode == wire.Strict {
+		if err := checkDMXLength(n); err != nil {
+			return err
+		}
+		if len(body) < n {
+			return &wire.FieldError{Field: "Data", Err: io.ErrUnexpectedEOF}
+		}
+		if len(body) > n {
+			return trailingError("DMX", len(body)-n)
+		}
+	}
+
+	// A lenient message may be shorter than its Length says, which is
+	// corrected to the data it holds.
+	if n > len(body) {
+		n = len(body)
+		p.Length = uint16(n)
+	}
+
+	p.Data = append(p.Data[:0], body[:n]...)
+	return nil
 }
@@ -0,0 +1,306 @@
+package artnet
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"lyra.codes/blinken/artnet/wire"
+)
+
+var (
+	firmwareMasterHeader = Header{Operation: OpFirmwareMaster}
+	firmwareReplyHeader  = Header{Operation: OpFirmwareReply}
+)
+
+// FirmwareBlockSize is the number of bytes of firmware in each block.
+const FirmwareBlockSize = 1024
+
+// FirmwareType is the position of a block within an upload, and whether the
+// upload is node firmware or a UBEA.
+type FirmwareType uint8
+
+const (
+	FirmFirst FirmwareType = 0x00
+	FirmCont  FirmwareType = 0x01
+	FirmLast  FirmwareType = 0x02
+	UbeaFirst FirmwareType = 0x03
+	UbeaCont  FirmwareType = 0x04
+	UbeaLast  FirmwareType = 0x05
+)
+
+// Last reports whether the block is the last of its upload.
+func (t FirmwareType) Last() bool {
+	return t == FirmLast || t == UbeaLast
+}
+
+// UBEA reports whether the block is part of a UBEA upload.
+func (t FirmwareType) UBEA() bool {
+	return t >= UbeaFirst && t <= UbeaLast
+}
+
+// FirmwareMaster is the contents of an OpFirmwareMaster message, which
+// carries one block of a firmware upload.
+type FirmwareMaster struct {
+	Header
+	Version Version
+	_       [2]byte `wire:"skip"`
+	Type    FirmwareType
+	BlockID uint8
+
+	// Length is the length of the whole upload in 16-bit words.
+	Length uint32
+	_      [20]byte `wire:"skip"`
+
+	// Data is the block's firmware. It is padded to FirmwareBlockSize.
+	Data []byte `wire:"size=1024"`
+}
+
+// NewFirmwareMaster creates a FirmwareMaster operation carrying one block.
+func NewFirmwareMaster(typ FirmwareType, block uint8, length uint32, data []byte) *FirmwareMaster {
+	return &FirmwareMaster{
+		Header:  firmwareMasterHeader,
+		Version: Version14,
+		Type:    typ,
+		BlockID: block,
+		Length:  length,
+		Data:    data,
+	}
+}
+
+func (p *FirmwareMaster) Read(r wire.Reader) error {
+	return wire.Decode(r, p)
+}
+
+func (p *FirmwareMaster) Write(w io.Writer) error {
+	return wire.Encode(w, p)
+}
+
+// FirmwareReplyType is a node's response to a FirmwareMaster.
+type FirmwareReplyType uint8
+
+const (
+	FirmBlockGood FirmwareReplyType = 0x00
+	FirmAllGood   FirmwareReplyType = 0x01
+	FirmFail      FirmwareReplyType = 0xFF
+)
+
+// FirmwareReply is the contents of an OpFirmwareReply message.
+type FirmwareReply struct {
+	Header
+	Version Version
+	_       [2]byte `wire:"skip"`
+	Type    FirmwareReplyType
+	_       [21]byte `wire:"skip"`
+}
+
+// NewFirmwareReply creates a new FirmwareReply operation.
+func NewFirmwareReply(typ FirmwareReplyType) *FirmwareReply {
+	return &FirmwareReply{
+		Header:  firmwareReplyHeader,
+		Version: Version14,
+		Type:    typ,
+	}
+}
+
+func (p *FirmwareReply) Read(r wire.Reader) error {
+	return wire.Decode(r, p)
+}
+
+func (p *FirmwareReply) Write(w io.Writer) error {
+	return wire.Encode(w, p)
+}
+
+// ErrFirmwareFailed is returned when a node rejects a firmware upload.
+var ErrFirmwareFailed = errors.New("node rejected the firmware upload")
+
+const (
+	// DefaultFirmwareRetries is how many times a block is resent when the
+	// node doesn't reply to it.
+	DefaultFirmwareRetries = 3
+	// DefaultFirmwareTimeout is how long to wait for a node to reply to a block.
+	DefaultFirmwareTimeout = 2 * time.Second
+)
+
+type upload struct {
+	ubea     bool
+	retries  int
+	timeout  time.Duration
+	progress func(sent, total int)
+}
+
+type UploadOption func(u *upload)
+
+// UploadUBEA uploads a User Bios Extension Area rather than node firmware.
+func UploadUBEA() UploadOption {
+	return func(u *upload) {
+		u.ubea = true
+	}
+}
+
+// UploadRetries sets how many times a block is resent when the node doesn't
+// reply to it.
+func UploadRetries(n int) UploadOption {
+	return func(u *upload) {
+		u.retries = n
+	}
+}
+
+// UploadTimeout sets how long to wait for a node to reply to each block.
+func UploadTimeout(d time.Duration) UploadOption {
+	return func(u *upload) {
+		u.timeout = d
+	}
+}
+
+// UploadProgress reports the number of bytes the node has accepted after
+// each block.
+func UploadProgress(f func(sent, total int)) UploadOption {
+	return func(u *upload) {
+		u.progress = f
+	}
+}
+
+// UploadFirmware uploads a firmware image to a node. The image is split into
+// blocks of FirmwareBlockSize, each of which must be acknowledged by the node
+// before the next is sent.
+//
+// No block type is both first and last, so an image which fits in one block
+// is followed by an empty last block; the node knows the image's size from
+// Length. The 8-bit BlockID wraps to 0 after block 255 of larger images, as
+// nodes count blocks by their type rather than their ID.
+func UploadFirmware(ctx context.Context, t Transport, node *Node, image []byte, options ...UploadOption) error {
+	u := &upload{
+		retries: DefaultFirmwareRetries,
+		timeout: DefaultFirmwareTimeout,
+	}
+	for _, opt := range options {
+		opt(u)
+	}
+
+	if len(image) == 0 {
+		return errors.New("firmware image is empty")
+	}
+	if len(image)%2 != 0 {
+		return errors.New("firmware image must be a whole number of 16-bit words")
+	}
+
+	sub := t.Subscribe(OpFirmwareReply)
+	defer sub.Close()
+
+	blocks := (len(image) + FirmwareBlockSize - 1) / FirmwareBlockSize
+	if blocks == 1 {
+		blocks = 2
+	}
+	length := uint32(len(image) / 2)
+
+	for i := 0; i < blocks; i++ {
+		start := i * FirmwareBlockSize
+		if start > len(image) {
+			start = len(image)
+		}
+		end := start + FirmwareBlockSize
+		if end > len(image) {
+			end = len(image)
+		}
+
+		last := i == blocks-1
+		p := NewFirmwareMaster(u.blockType(i, last), uint8(i), length, image[start:end])
+
+		if err := u.send(ctx, t, sub, node, p); err != nil {
+			return fmt.Errorf("block %d of %d: %w", i+1, blocks, err)
+		}
+
+		if u.progress != nil {
+			u.progress(end, len(image))
+		}
+	}
+
+	return nil
+}
+
+func (u *upload) blockType(i int, last bool) FirmwareType {
+	typ := FirmCont
+	switch {
+	case i == 0:
+		typ = FirmFirst
+	case last:
+		typ = FirmLast
+	}
+
+	if u.ubea {
+		typ += UbeaFirst
+	}
+	return typ
+}
+
+// send sends a block until the node acknowledges it or the retries run out.
+func (u *upload) send(ctx context.Context, t Transport, sub *Subscription, node *Node, p *FirmwareMaster) error {
+	expect := FirmBlockGood
+	if p.Type.Last() {
+		expect = FirmAllGood
+	}
+
+	for attempt := 0; attempt <= u.retries; attempt++ {
+		drain(sub)
+		if err := t.Send(node.NetworkAddress, p); err != nil {
+			return err
+		}
+
+		reply, err := u.await(ctx, sub, node)
+		if err != nil {
+			return err
+		}
+		if reply == nil {
+			continue
+		}
+
+		switch reply.Type {
+		case expect:
+			return nil
+		case FirmFail:
+			return ErrFirmwareFailed
+		default:
+			return fmt.Errorf("unexpected firmware reply 0x%02x", uint8(reply.Type))
+		}
+	}
+
+	return fmt.Errorf("no reply from %s after %d attempts", node.ShortName, u.retries+1)
+}
+
+// await waits for a reply from the node, returning nil if none arrives
+// before the timeout.
+func (u *upload) await(ctx context.Context, sub *Subscription, node *Node) (*FirmwareReply, error) {
+	timer := time.NewTimer(u.timeout)
+	defer timer.Stop()
+
+	for {
+		select {
+		case msg, ok := <-sub.C:
+			if !ok {
+				return nil, errors.New("transport closed")
+			}
+			if !msg.From.IP.Equal(node.NetworkAddress.IP) {
+				continue
+			}
+			return msg.Packet.(*FirmwareReply), nil
+		case <-timer.C:
+			return nil, nil
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// drain discards late replies to earlier attempts.
+func drain(sub *Subscription) {
+	for {
+		select {
+		case <-sub.C:
+		default:
+			return
+		}
+	}
+}
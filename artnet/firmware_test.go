@@ -0,0 +1,240 @@
+package artnet
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net"
+	"testing"
+	"time"
+)
+
+// firmwareNode simulates a node receiving a firmware upload over a Transport.
+type firmwareNode struct {
+	addr *net.UDPAddr
+	sub  *Subscription
+	c    chan Message
+
+	// drop is the number of blocks to ignore before replying, to exercise
+	// retries.
+	drop int
+	// fail makes the node reject the block with this ID.
+	fail int
+
+	types  []FirmwareType
+	length uint32
+	next   uint8
+	image  []byte
+	done   bool
+}
+
+func newFirmwareNode() *firmwareNode {
+	c := make(chan Message, subscriptionBuffer)
+	n := &firmwareNode{
+		addr: &net.UDPAddr{IP: net.IPv4(2, 0, 0, 10), Port: Port},
+		c:    c,
+		fail: -1,
+	}
+	n.sub = &Subscription{C: c, c: c, ops: []Operation{OpFirmwareReply}, cancel: func(*Subscription) {}}
+	return n
+}
+
+func (n *firmwareNode) Node() *Node {
+	return &Node{NetworkAddress: n.addr, ShortName: "sim"}
+}
+
+func (n *firmwareNode) Send(to *net.UDPAddr, packet Packet) error {
+	if !to.IP.Equal(n.addr.IP) {
+		return errors.New("unexpected destination")
+	}
+
+	// Round-trip the packet through the wire format, as a real node would.
+	buf := bytes.Buffer{}
+	if err := packet.Write(&buf); err != nil {
+		return err
+	}
+	p := &FirmwareMaster{}
+	if err := p.Read(bytes.NewBuffer(buf.Bytes())); err != nil {
+		return err
+	}
+
+	if n.drop > 0 {
+		n.drop--
+		return nil
+	}
+
+	reply := FirmBlockGood
+	switch {
+	case int(p.BlockID) == n.fail:
+		reply = FirmFail
+	case p.Type == FirmFirst || p.Type == UbeaFirst:
+		n.image = n.image[:0]
+		n.types = n.types[:0]
+		n.length = p.Length
+		n.next = 0
+		fallthrough
+	case p.BlockID == n.next:
+		n.image = append(n.image, p.Data...)
+		n.types = append(n.types, p.Type)
+		n.next++
+	case p.BlockID == n.next-1:
+		// A retry of a block whose reply was lost.
+	default:
+		reply = FirmFail
+	}
+
+	if reply != FirmFail && p.Type.Last() {
+		n.image = n.image[:n.length*2]
+		n.done = true
+		reply = FirmAllGood
+	}
+
+	n.c <- Message{From: n.addr, Packet: NewFirmwareReply(reply)}
+	return nil
+}
+
+func (n *firmwareNode) Nodes() <-chan *Node { return nil }
+
+func (n *firmwareNode) Subscribe(ops ...Operation) *Subscription { return n.sub }
+
+func (n *firmwareNode) Diagnostics(DiagnosticPriority) *Subscription { return nil }
+
+func (n *firmwareNode) Triggers(uint16) *Subscription { return nil }
+
+func (n *firmwareNode) Universes(...Address) *Subscription { return nil }
+
+func firmwareImage(size int) []byte {
+	image := make([]byte, size)
+	for i := range image {
+		image[i] = byte(i * 7)
+	}
+	return image
+}
+
+func TestUploadFirmware(t *testing.T) {
+	node := newFirmwareNode()
+	image := firmwareImage(3*FirmwareBlockSize + 200)
+
+	var progress []int
+	err := UploadFirmware(context.Background(), node, node.Node(), image,
+		UploadProgress(func(sent, total int) {
+			if total != len(image) {
+				t.Errorf("progress total = %d, want %d", total, len(image))
+			}
+			progress = append(progress, sent)
+		}))
+	if err != nil {
+		t.Fatalf("UploadFirmware: %v", err)
+	}
+
+	if !node.done {
+		t.Fatal("node did not receive the last block")
+	}
+	if !bytes.Equal(node.image, image) {
+		t.Fatal("node recorded a different image")
+	}
+
+	wantTypes := []FirmwareType{FirmFirst, FirmCont, FirmCont, FirmLast}
+	if len(node.types) != len(wantTypes) {
+		t.Fatalf("node received %d blocks, want %d", len(node.types), len(wantTypes))
+	}
+	for i, typ := range wantTypes {
+		if node.types[i] != typ {
+			t.Errorf("block %d type = %d, want %d", i, node.types[i], typ)
+		}
+	}
+
+	wantProgress := []int{1024, 2048, 3072, len(image)}
+	for i, sent := range wantProgress {
+		if i >= len(progress) || progress[i] != sent {
+			t.Fatalf("progress = %v, want %v", progress, wantProgress)
+		}
+	}
+}
+
+func TestUploadFirmwareSingleBlock(t *testing.T) {
+	node := newFirmwareNode()
+	image := firmwareImage(200)
+
+	if err := UploadFirmware(context.Background(), node, node.Node(), image); err != nil {
+		t.Fatalf("UploadFirmware: %v", err)
+	}
+
+	if len(node.types) != 2 || node.types[0] != FirmFirst || node.types[1] != FirmLast {
+		t.Errorf("block types = %v, want [%d %d]", node.types, FirmFirst, FirmLast)
+	}
+	if !bytes.Equal(node.image, image) {
+		t.Fatal("node recorded a different image")
+	}
+}
+
+func TestUploadFirmwareLarge(t *testing.T) {
+	node := newFirmwareNode()
+	image := firmwareImage(300 * FirmwareBlockSize)
+
+	if err := UploadFirmware(context.Background(), node, node.Node(), image); err != nil {
+		t.Fatalf("UploadFirmware: %v", err)
+	}
+
+	if len(node.types) != 300 || node.types[0] != FirmFirst || node.types[299] != FirmLast {
+		t.Errorf("node received %d blocks", len(node.types))
+	}
+	if node.next != 300%256 {
+		t.Errorf("last block ID = %d, want %d", node.next-1, 300%256-1)
+	}
+	if !bytes.Equal(node.image, image) {
+		t.Fatal("node recorded a different image")
+	}
+}
+
+func TestUploadFirmwareUBEA(t *testing.T) {
+	node := newFirmwareNode()
+	image := firmwareImage(2 * FirmwareBlockSize)
+
+	if err := UploadFirmware(context.Background(), node, node.Node(), image, UploadUBEA()); err != nil {
+		t.Fatalf("UploadFirmware: %v", err)
+	}
+
+	if node.types[0] != UbeaFirst || node.types[1] != UbeaLast {
+		t.Errorf("block types = %v, want [%d %d]", node.types, UbeaFirst, UbeaLast)
+	}
+	if !bytes.Equal(node.image, image) {
+		t.Fatal("node recorded a different image")
+	}
+}
+
+func TestUploadFirmwareRetries(t *testing.T) {
+	node := newFirmwareNode()
+	node.drop = 2
+	image := firmwareImage(2 * FirmwareBlockSize)
+
+	err := UploadFirmware(context.Background(), node, node.Node(), image,
+		UploadTimeout(10*time.Millisecond), UploadRetries(2))
+	if err != nil {
+		t.Fatalf("UploadFirmware: %v", err)
+	}
+	if !bytes.Equal(node.image, image) {
+		t.Fatal("node recorded a different image")
+	}
+}
+
+func TestUploadFirmwareTimeout(t *testing.T) {
+	node := newFirmwareNode()
+	node.drop = 3
+
+	err := UploadFirmware(context.Background(), node, node.Node(), firmwareImage(FirmwareBlockSize),
+		UploadTimeout(10*time.Millisecond), UploadRetries(2))
+	if err == nil {
+		t.Fatal("UploadFirmware succeeded without replies")
+	}
+}
+
+func TestUploadFirmwareFail(t *testing.T) {
+	node := newFirmwareNode()
+	node.fail = 1
+
+	err := UploadFirmware(context.Background(), node, node.Node(), firmwareImage(3*FirmwareBlockSize))
+	if !errors.Is(err, ErrFirmwareFailed) {
+		t.Fatalf("UploadFirmware error = %v, want %v", err, ErrFirmwareFailed)
+	}
+}
@@ -0,0 +1,113 @@
+package artnet
+
+import (
+	"bytes"
+	"encoding/binary"
+	"reflect"
+	"testing"
+
+	"lyra.codes/blinken/artnet/wire"
+)
+
+// fuzzPacket fuzzes the Read method of the packet for an operation, seeded
+// with the spec packets for it. Any packet which Read accepts must encode,
+// and decode again to the same packet.
+func fuzzPacket(f *testing.F, op Operation) {
+	for _, g := range specPackets {
+		data := readSpec(f, g.name)
+		if operation(data) == op {
+			f.Add(data)
+		}
+	}
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		// Lenient parsing may accept anything, but mustn't panic.
+		_ = readPacket(newPacket(Header{Operation: op}), data, wire.Lenient)
+
+		p := newPacket(Header{Operation: op})
+		if err := p.Read(bytes.NewBuffer(data)); err != nil {
+			return
+		}
+
+		roundTrip(t, op, p)
+	})
+}
+
+// operation returns the operation in the header of a packet.
+func operation(data []byte) Operation {
+	return Operation(binary.LittleEndian.Uint16(data[len(Magic):]))
+}
+
+func roundTrip(t *testing.T, op Operation, p Packet) {
+	if reply, ok := p.(*PollReply); ok {
+		reply.ToNode()
+	}
+
+	b, err := encode(p)
+	if err != nil {
+		t.Fatalf("Write(%+v): %v", p, err)
+	}
+
+	q := newPacket(Header{Operation: op})
+	if err := q.Read(bytes.NewBuffer(b)); err != nil {
+		t.Fatalf("Read(Write(p)): %v", err)
+	}
+	if !reflect.DeepEqual(p, q) {
+		t.Fatalf("Read(Write(p)) = %+v, want %+v", q, p)
+	}
+
+	// Fast paths must agree with Read and Write.
+	if a, ok := p.(Appender); ok {
+		fast, err := a.AppendBinary(nil)
+		if err != nil {
+			t.Fatalf("AppendBinary: %v", err)
+		}
+		if !bytes.Equal(fast, b) {
+			t.Fatalf("AppendBinary = %x, want %x", fast, b)
+		}
+	}
+	if _, ok := p.(binaryUnmarshaler); ok {
+		q := newPacket(Header{Operation: op})
+		if err := q.(binaryUnmarshaler).unmarshalBinary(b, wire.Strict); err != nil {
+			t.Fatalf("UnmarshalBinary(Write(p)): %v", err)
+		}
+		if !reflect.DeepEqual(p, q) {
+			t.Fatalf("UnmarshalBinary(Write(p)) = %+v, want %+v", q, p)
+		}
+	}
+}
+
+func FuzzPoll(f *testing.F)           { fuzzPacket(f, OpPoll) }
+func FuzzPollReply(f *testing.F)      { fuzzPacket(f, OpPollReply) }
+func FuzzDMX(f *testing.F)            { fuzzPacket(f, OpDMX) }
+func FuzzSync(f *testing.F)           { fuzzPacket(f, OpSync) }
+func FuzzDiagData(f *testing.F)       { fuzzPacket(f, OpDiagData) }
+func FuzzCommand(f *testing.F)        { fuzzPacket(f, OpCommand) }
+func FuzzInput(f *testing.F)          { fuzzPacket(f, OpInput) }
+func FuzzTodRequest(f *testing.F)     { fuzzPacket(f, OpDeviceTableRequest) }
+func FuzzTodData(f *testing.F)        { fuzzPacket(f, OpDeviceTableData) }
+func FuzzTodControl(f *testing.F)     { fuzzPacket(f, OpDeviceTableControl) }
+func FuzzRDM(f *testing.F)            { fuzzPacket(f, OpRDM) }
+func FuzzTimeCode(f *testing.F)       { fuzzPacket(f, OpTimeCode) }
+func FuzzTrigger(f *testing.F)        { fuzzPacket(f, OpTrigger) }
+func FuzzFirmwareMaster(f *testing.F) { fuzzPacket(f, OpFirmwareMaster) }
+func FuzzFirmwareReply(f *testing.F)  { fuzzPacket(f, OpFirmwareReply) }
+func FuzzIpProg(f *testing.F)         { fuzzPacket(f, OpIpProg) }
+func FuzzIpProgReply(f *testing.F)    { fuzzPacket(f, OpIpProgReply) }
+
+// FuzzReadPacket fuzzes decoding packets of any operation in both modes.
+func FuzzReadPacket(f *testing.F) {
+	for _, g := range specPackets {
+		f.Add(readSpec(f, g.name))
+	}
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		_, _ = ReadPacket(data, wire.Lenient)
+
+		p, err := ReadPacket(data, wire.Strict)
+		if err != nil {
+			return
+		}
+		roundTrip(t, operation(data), p)
+	})
+}
@@ -0,0 +1,117 @@
+package artnet
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+
+	"lyra.codes/blinken/artnet/wire"
+)
+
+var (
+	inputHeader = Header{Operation: OpInput}
+)
+
+// inputPorts is the number of ports an Input message controls.
+const inputPorts = 4
+
+// InputFlags controls one input port in an Input message.
+type InputFlags uint8
+
+const (
+	InputDisable InputFlags = 0x01
+)
+
+func (f InputFlags) Enabled(v InputFlags) bool {
+	return (v & f) != 0
+}
+
+// Input is the contents of an OpInput message, which enables or disables
+// the DMX inputs of a node.
+type Input struct {
+	Header
+	Version   Version
+	_         [1]byte `wire:"skip"`
+	BindIndex uint8
+	NumPorts  uint16
+	Inputs    [inputPorts]InputFlags
+}
+
+// NewInput creates a new Input operation, enabling every input port.
+func NewInput(bindIndex uint8, numPorts uint16) *Input {
+	return &Input{
+		Header:    inputHeader,
+		Version:   Version14,
+		BindIndex: bindIndex,
+		NumPorts:  numPorts,
+	}
+}
+
+// SetInput creates an Input operation which enables or disables one of the
+// node's input ports, leaving the others as the node last reported them.
+func (n *Node) SetInput(port int, enabled bool) (*Input, error) {
+	if port < 0 || port >= len(n.Ports) || port >= inputPorts {
+		return nil, fmt.Errorf("node %s has no port %d", n.ShortName, port)
+	}
+
+	p := NewInput(n.BindIndex, uint16(len(n.Ports)))
+	for i, np := range n.Ports {
+		if i < inputPorts && !np.InputEnabled() {
+			p.Inputs[i] = InputDisable
+		}
+	}
+
+	if enabled {
+		p.Inputs[port] &^= InputDisable
+	} else {
+		p.Inputs[port] |= InputDisable
+	}
+
+	return p, nil
+}
+
+func (p *Input) Read(r wire.Reader) error {
+	return wire.Decode(r, p)
+}
+
+func (p *Input) Write(w io.Writer) error {
+	return wire.Encode(w, p)
+}
+
+// EnableInput enables or disables one of a node's input ports, and waits for
+// the PollReply in which the node confirms the port's new state.
+func EnableInput(ctx context.Context, t Transport, node *Node, port int, enabled bool) (*Node, error) {
+	p, err := node.SetInput(port, enabled)
+	if err != nil {
+		return nil, err
+	}
+
+	sub := t.Subscribe(OpPollReply)
+	defer sub.Close()
+
+	if err := t.Send(node.NetworkAddress, p); err != nil {
+		return nil, err
+	}
+
+	for {
+		select {
+		case msg, ok := <-sub.C:
+			if !ok {
+				return nil, fmt.Errorf("transport closed")
+			}
+
+			reply := msg.Packet.(*PollReply)
+			if !bytes.Equal(reply.MAC, node.MAC) || reply.BindIndex != node.BindIndex {
+				continue
+			}
+
+			updated := reply.ToNode()
+			if port < len(updated.Ports) && updated.Ports[port].InputEnabled() == enabled {
+				return updated, nil
+			}
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
@@ -0,0 +1,273 @@
+package artnet
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"time"
+
+	"lyra.codes/blinken/artnet/wire"
+)
+
+var (
+	ipProgHeader      = Header{Operation: OpIpProg}
+	ipProgReplyHeader = Header{Operation: OpIpProgReply}
+)
+
+// IpProgCommand is the set of changes requested by an IpProg message.
+type IpProgCommand uint8
+
+const (
+	IpProgEnable  IpProgCommand = 0x80
+	IpProgDHCP    IpProgCommand = 0x40
+	IpProgGateway IpProgCommand = 0x10
+	IpProgReset   IpProgCommand = 0x08
+	IpProgIP      IpProgCommand = 0x04
+	IpProgMask    IpProgCommand = 0x02
+	IpProgPort    IpProgCommand = 0x01
+)
+
+func (f IpProgCommand) Enabled(v IpProgCommand) bool {
+	return (v & f) != 0
+}
+
+func (f IpProgCommand) Set(v *IpProgCommand) {
+	*v |= f
+}
+
+// IpProg is the contents of an OpIpProg message, which reprograms the IP
+// configuration of a node. An IpProg with no command bits set asks the node
+// for its current configuration.
+type IpProg struct {
+	Header
+	Version Version
+	_       [2]byte `wire:"skip"`
+	Command IpProgCommand
+	_       [1]byte `wire:"skip"`
+	IP      net.IP
+	Mask    net.IP
+	Port    uint16
+	Gateway net.IP
+	_       [4]byte `wire:"skip"`
+}
+
+// NewIpProg creates a new IpProg operation which changes nothing.
+func NewIpProg() *IpProg {
+	return &IpProg{
+		Header:  ipProgHeader,
+		Version: Version14,
+		IP:      net.IPv4zero,
+		Mask:    net.IPv4zero,
+		Port:    Port,
+		Gateway: net.IPv4zero,
+	}
+}
+
+func (p *IpProg) Read(r wire.Reader) error {
+	return wire.Decode(r, p)
+}
+
+func (p *IpProg) Write(w io.Writer) error {
+	return wire.Encode(w, p)
+}
+
+// IpProgStatus is the status reported by an IpProgReply.
+type IpProgStatus uint8
+
+const (
+	IpProgStatusDHCP IpProgStatus = 0x40
+)
+
+func (f IpProgStatus) Enabled(v IpProgStatus) bool {
+	return (v & f) != 0
+}
+
+// IpProgReply is the contents of an OpIpProgReply message, in which a node
+// reports its IP configuration after an IpProg.
+type IpProgReply struct {
+	Header
+	Version Version
+	_       [4]byte `wire:"skip"`
+	IP      net.IP
+	Mask    net.IP
+	Port    uint16
+	Status  IpProgStatus
+	_       [1]byte `wire:"skip"`
+	Gateway net.IP
+	_       [2]byte `wire:"skip"`
+}
+
+// NewIpProgReply creates a new IpProgReply operation.
+func NewIpProgReply(ip, mask, gateway net.IP, dhcp bool) *IpProgReply {
+	p := &IpProgReply{
+		Header:  ipProgReplyHeader,
+		Version: Version14,
+		IP:      ip,
+		Mask:    mask,
+		Port:    Port,
+		Gateway: gateway,
+	}
+	if dhcp {
+		p.Status |= IpProgStatusDHCP
+	}
+
+	return p
+}
+
+func (p *IpProgReply) Read(r wire.Reader) error {
+	return wire.Decode(r, p)
+}
+
+func (p *IpProgReply) Write(w io.Writer) error {
+	return wire.Encode(w, p)
+}
+
+// IPConfig is an IP configuration to program into a node. Nil fields are
+// left unchanged.
+type IPConfig struct {
+	IP      net.IP
+	Mask    net.IPMask
+	Gateway net.IP
+
+	// DHCP makes the node get its configuration from a DHCP server; the
+	// other fields are ignored.
+	DHCP bool
+	// Reset returns the node to its factory configuration; the other
+	// fields are ignored.
+	Reset bool
+}
+
+// IpProg creates the IpProg operation which programs the configuration.
+func (c IPConfig) IpProg() *IpProg {
+	p := NewIpProg()
+	IpProgEnable.Set(&p.Command)
+
+	switch {
+	case c.Reset:
+		IpProgReset.Set(&p.Command)
+	case c.DHCP:
+		IpProgDHCP.Set(&p.Command)
+	default:
+		if c.IP != nil {
+			IpProgIP.Set(&p.Command)
+			p.IP = c.IP
+		}
+		if c.Mask != nil {
+			IpProgMask.Set(&p.Command)
+			p.Mask = net.IP(c.Mask)
+		}
+		if c.Gateway != nil {
+			IpProgGateway.Set(&p.Command)
+			p.Gateway = c.Gateway
+		}
+	}
+
+	return p
+}
+
+// Verify checks that a node's reply shows it has taken the configuration.
+func (c IPConfig) Verify(reply *IpProgReply) error {
+	if c.Reset {
+		return nil
+	}
+	if c.DHCP {
+		if !IpProgStatusDHCP.Enabled(reply.Status) {
+			return fmt.Errorf("node did not enable DHCP")
+		}
+		return nil
+	}
+
+	if c.IP != nil && !c.IP.Equal(reply.IP) {
+		return fmt.Errorf("node reports IP %s, not %s", reply.IP, c.IP)
+	}
+	if c.Mask != nil && !bytes.Equal(c.Mask, reply.Mask.To4()) {
+		return fmt.Errorf("node reports subnet mask %s, not %s", reply.Mask, net.IP(c.Mask))
+	}
+	if c.Gateway != nil && !c.Gateway.Equal(reply.Gateway) {
+		return fmt.Errorf("node reports gateway %s, not %s", reply.Gateway, c.Gateway)
+	}
+
+	return nil
+}
+
+// ipProgPollInterval is how often ProgramIP polls for a node until it
+// replies from its new address.
+var ipProgPollInterval = time.Second
+
+// ProgramIP reprograms the IP configuration of a node and verifies the
+// node's reply. It then polls for the node by its MAC address until it
+// replies from its new address, and returns the node as found there.
+//
+// Replies are only accepted from the node's old address or the address it's
+// being programmed with; when programming DHCP or a reset, the node must
+// reply from its old address.
+func ProgramIP(ctx context.Context, t Transport, node *Node, config IPConfig) (*Node, error) {
+	if config.Mask != nil && len(config.Mask) != net.IPv4len {
+		return nil, fmt.Errorf("subnet mask %s is not an IPv4 mask", config.Mask)
+	}
+
+	var newIP net.IP
+	if !config.Reset && !config.DHCP {
+		newIP = config.IP
+	}
+	fromNode := func(ip net.IP) bool {
+		return ip.Equal(node.NetworkAddress.IP) || (newIP != nil && ip.Equal(newIP))
+	}
+
+	sub := t.Subscribe(OpIpProgReply, OpPollReply)
+	defer sub.Close()
+
+	if err := t.Send(node.NetworkAddress, config.IpProg()); err != nil {
+		return nil, err
+	}
+
+	// The ticker only starts polling once the node has been programmed.
+	ticker := time.NewTicker(ipProgPollInterval)
+	defer ticker.Stop()
+
+	programmed := false
+	for {
+		select {
+		case msg, ok := <-sub.C:
+			if !ok {
+				return nil, fmt.Errorf("transport closed")
+			}
+
+			switch p := msg.Packet.(type) {
+			case *IpProgReply:
+				if programmed || !fromNode(msg.From.IP) {
+					continue
+				}
+				if err := config.Verify(p); err != nil {
+					return nil, fmt.Errorf("programming %s: %w", node.ShortName, err)
+				}
+				programmed = true
+
+				if err := t.Send(Broadcast, NewPoll()); err != nil {
+					return nil, err
+				}
+			case *PollReply:
+				if !programmed || !bytes.Equal(p.MAC, node.MAC) {
+					continue
+				}
+				// Until the node has moved, it may still reply from its
+				// old address.
+				if newIP != nil && !p.Node.IP.Equal(newIP) {
+					continue
+				}
+				return p.ToNode(), nil
+			}
+		case <-ticker.C:
+			if !programmed {
+				continue
+			}
+			if err := t.Send(Broadcast, NewPoll()); err != nil {
+				return nil, err
+			}
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
@@ -0,0 +1,135 @@
+package artnet
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+)
+
+// ipNode simulates a node being reprogrammed over a Transport.
+type ipNode struct {
+	addr *net.UDPAddr
+	mac  net.HardwareAddr
+	sub  *Subscription
+	c    chan Message
+
+	// stale is the number of polls answered from the old address after
+	// the node has been programmed.
+	stale int
+	polls int
+}
+
+func newIPNode() *ipNode {
+	c := make(chan Message, subscriptionBuffer)
+	n := &ipNode{
+		addr: &net.UDPAddr{IP: net.IPv4(2, 0, 0, 10), Port: Port},
+		mac:  net.HardwareAddr{0x02, 0x00, 0x00, 0x00, 0x00, 0x0a},
+		c:    c,
+	}
+	n.sub = &Subscription{C: c, c: c, ops: []Operation{OpIpProgReply, OpPollReply}, cancel: func(*Subscription) {}}
+	return n
+}
+
+func (n *ipNode) Node() *Node {
+	return &Node{NetworkAddress: n.addr, ShortName: "sim", MAC: n.mac}
+}
+
+func (n *ipNode) pollReply(ip net.IP, mac net.HardwareAddr) Message {
+	p := testPollReply()
+	p.Node = net.UDPAddr{IP: ip, Port: Port}
+	p.MAC = mac
+	return Message{From: &p.Node, Packet: p}
+}
+
+func (n *ipNode) Send(to *net.UDPAddr, packet Packet) error {
+	switch p := packet.(type) {
+	case *IpProg:
+		// Another node's reply, which happens to claim the new address,
+		// arrives first.
+		other := &net.UDPAddr{IP: net.IPv4(2, 0, 0, 99), Port: Port}
+		n.c <- Message{From: other, Packet: NewIpProgReply(p.IP, net.IPv4(255, 255, 255, 255), net.IPv4zero, false)}
+		n.c <- Message{From: n.addr, Packet: NewIpProgReply(p.IP, p.Mask, p.Gateway, false)}
+		n.addr = &net.UDPAddr{IP: p.IP, Port: Port}
+	case *Poll:
+		n.polls++
+		n.c <- n.pollReply(net.IPv4(2, 0, 0, 11), net.HardwareAddr{0x02, 0, 0, 0, 0, 0x0b})
+		if n.polls <= n.stale {
+			n.c <- n.pollReply(net.IPv4(2, 0, 0, 10), n.mac)
+		} else {
+			n.c <- n.pollReply(n.addr.IP, n.mac)
+		}
+	}
+	return nil
+}
+
+func (n *ipNode) Nodes() <-chan *Node { return nil }
+
+func (n *ipNode) Subscribe(ops ...Operation) *Subscription { return n.sub }
+
+func (n *ipNode) Diagnostics(DiagnosticPriority) *Subscription { return nil }
+
+func (n *ipNode) Triggers(uint16) *Subscription { return nil }
+
+func (n *ipNode) Universes(...Address) *Subscription { return nil }
+
+func TestProgramIP(t *testing.T) {
+	defer func(d time.Duration) { ipProgPollInterval = d }(ipProgPollInterval)
+	ipProgPollInterval = 10 * time.Millisecond
+
+	for _, stale := range []int{0, 3} {
+		n := newIPNode()
+		n.stale = stale
+
+		config := IPConfig{IP: net.IPv4(10, 0, 0, 5).To4(), Mask: net.CIDRMask(8, 32)}
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		node, err := ProgramIP(ctx, n, n.Node(), config)
+		cancel()
+		if err != nil {
+			t.Fatalf("%d stale replies: %v", stale, err)
+		}
+
+		if !node.NetworkAddress.IP.Equal(config.IP) {
+			t.Errorf("%d stale replies: node found at %s, want %s", stale, node.NetworkAddress.IP, config.IP)
+		}
+		if n.polls != stale+1 {
+			t.Errorf("%d stale replies: polled %d times, want %d", stale, n.polls, stale+1)
+		}
+	}
+}
+
+func TestProgramIPTimeout(t *testing.T) {
+	defer func(d time.Duration) { ipProgPollInterval = d }(ipProgPollInterval)
+	ipProgPollInterval = 10 * time.Millisecond
+
+	n := newIPNode()
+	n.stale = 1 << 30
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	_, err := ProgramIP(ctx, n, n.Node(), IPConfig{IP: net.IPv4(10, 0, 0, 5).To4()})
+	if err != context.DeadlineExceeded {
+		t.Errorf("error is %v, want %v", err, context.DeadlineExceeded)
+	}
+	if n.polls < 2 {
+		t.Errorf("polled %d times before giving up", n.polls)
+	}
+}
+
+func TestProgramIPVerify(t *testing.T) {
+	n := newIPNode()
+
+	// The node replies with a mask it wasn't programmed with.
+	config := IPConfig{IP: net.IPv4(10, 0, 0, 5).To4(), Mask: net.CIDRMask(8, 32)}
+	prog := config.IpProg()
+	prog.Mask = net.IPv4(255, 255, 0, 0)
+	n.c <- Message{From: n.addr, Packet: NewIpProgReply(prog.IP, prog.Mask, prog.Gateway, false)}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if _, err := ProgramIP(ctx, n, n.Node(), config); err == nil {
+		t.Error("ProgramIP accepted a reply with the wrong mask")
+	}
+}
@@ -0,0 +1,229 @@
+package artnet
+
+import (
+	"net"
+	"sync"
+	"time"
+
+	"lyra.codes/blinken/dmx"
+)
+
+// MergeMode is how a node combines DMX from more than one controller.
+type MergeMode uint8
+
+const (
+	// MergeHTP outputs the highest value of each channel across sources.
+	MergeHTP MergeMode = iota
+	// MergeLTP outputs each channel from the source which changed it
+	// last. A source which starts sending changes all of its channels.
+	MergeLTP
+)
+
+const (
+	// MergeTimeout is how long a source may be silent before a node stops
+	// merging its data.
+	MergeTimeout = 10 * time.Second
+
+	// MergeSources is the number of sources a node merges on one port.
+	MergeSources = 2
+)
+
+// Merger merges OpDMX from multiple controllers targeting the same
+// port-address, as a node is required to do.
+type Merger struct {
+	mu    sync.Mutex
+	mode  MergeMode
+	ports map[Address]*mergePort
+}
+
+type mergePort struct {
+	mode    MergeMode
+	sources []*mergeSource
+	merged  dmx.Universe
+}
+
+type mergeSource struct {
+	ip   net.IP
+	seen time.Time
+	data dmx.Universe
+
+	// changed is when each channel of data last changed.
+	changed []time.Time
+}
+
+// NewMerger creates a Merger which merges ports in the given mode unless
+// SetMode changes it.
+func NewMerger(mode MergeMode) *Merger {
+	return &Merger{
+		mode:  mode,
+		ports: make(map[Address]*mergePort),
+	}
+}
+
+// SetMode changes the merge mode of a port.
+func (m *Merger) SetMode(addr Address, mode MergeMode) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	port := m.port(addr)
+	port.mode = mode
+	port.merge()
+}
+
+// Merge adds a DMX packet received from a controller and returns the merged
+// universe for its port-address, which is as long as the longest frame from
+// any source. Merge returns false if the packet was
+// ignored because two other sources are already merging into the port.
+func (m *Merger) Merge(from net.IP, p *DMX, now time.Time) (dmx.Universe, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	port := m.port(p.Address)
+	port.expire(now)
+
+	source := port.source(from)
+	if source == nil {
+		if len(port.sources) >= MergeSources {
+			return port.copy(), false
+		}
+
+		source = &mergeSource{ip: from}
+		port.sources = append(port.sources, source)
+	}
+
+	source.update(p.Data, now)
+	port.merge()
+
+	return port.copy(), true
+}
+
+// Expire drops sources that have been silent for longer than MergeTimeout.
+func (m *Merger) Expire(now time.Time) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, port := range m.ports {
+		port.expire(now)
+	}
+}
+
+// Universe returns a copy of the merged universe for a port-address.
+func (m *Merger) Universe(addr Address) dmx.Universe {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	port, ok := m.ports[addr]
+	if !ok {
+		return nil
+	}
+	return port.copy()
+}
+
+// Status returns the output status flags describing the merge state of a
+// port-address, for use in a PollReply.
+func (m *Merger) Status(addr Address) PortOutput {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var status PortOutput
+	port, ok := m.ports[addr]
+	if !ok {
+		return status
+	}
+
+	if len(port.sources) > 0 {
+		status |= PortOutputTransmitting
+	}
+	if len(port.sources) > 1 {
+		status |= PortOutputMerging
+	}
+	if port.mode == MergeLTP {
+		status |= PortOutputMergeLTP
+	}
+
+	return status
+}
+
+func (m *Merger) port(addr Address) *mergePort {
+	port, ok := m.ports[addr]
+	if !ok {
+		port = &mergePort{mode: m.mode}
+		m.ports[addr] = port
+	}
+	return port
+}
+
+func (p *mergePort) source(ip net.IP) *mergeSource {
+	for _, s := range p.sources {
+		if s.ip.Equal(ip) {
+			return s
+		}
+	}
+	return nil
+}
+
+func (p *mergePort) expire(now time.Time) {
+	kept := p.sources[:0]
+	for _, s := range p.sources {
+		if now.Sub(s.seen) <= MergeTimeout {
+			kept = append(kept, s)
+		}
+	}
+
+	if len(kept) != len(p.sources) {
+		for i := len(kept); i < len(p.sources); i++ {
+			p.sources[i] = nil
+		}
+		p.sources = kept
+		p.merge()
+	}
+}
+
+// merge recomputes the merged universe from every source.
+func (p *mergePort) merge() {
+	p.merged = p.merged[:0]
+	for _, s := range p.sources {
+		for len(p.merged) < len(s.data) {
+			p.merged = append(p.merged, 0)
+		}
+	}
+
+	for i := range p.merged {
+		var from *mergeSource
+		for _, s := range p.sources {
+			if i >= len(s.data) {
+				continue
+			}
+
+			switch {
+			case from == nil:
+				from = s
+			case p.mode == MergeLTP && !s.changed[i].Before(from.changed[i]):
+				from = s
+			case p.mode == MergeHTP && s.data[i] > from.data[i]:
+				from = s
+			}
+		}
+		p.merged[i] = from.data[i]
+	}
+}
+
+// update replaces a source's frame with data received at now, noting when
+// each channel changed.
+func (s *mergeSource) update(data dmx.Universe, now time.Time) {
+	for i, v := range data {
+		if i >= len(s.data) {
+			s.changed = append(s.changed, now)
+		} else if s.data[i] != v {
+			s.changed[i] = now
+		}
+	}
+
+	s.seen = now
+	s.data = append(s.data[:0], data...)
+	s.changed = s.changed[:len(data)]
+}
+
+func (p *mergePort) copy() dmx.Universe {
+	return append(dmx.Universe(nil), p.merged...)
+}
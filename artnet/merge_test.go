@@ -0,0 +1,191 @@
+package artnet
+
+import (
+	"bytes"
+	"net"
+	"testing"
+	"time"
+
+	"lyra.codes/blinken/dmx"
+)
+
+var (
+	mergeStart   = time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	controllerA  = net.IPv4(2, 0, 0, 1)
+	controllerB  = net.IPv4(2, 0, 0, 2)
+	controllerC  = net.IPv4(2, 0, 0, 3)
+	mergeAddress = Address(1)
+)
+
+// mergeStep is a DMX packet received by a Merger, and the universe it
+// should output afterwards.
+type mergeStep struct {
+	from  net.IP
+	after time.Duration
+	data  dmx.Universe
+
+	want     dmx.Universe
+	accepted bool
+}
+
+func TestMerger(t *testing.T) {
+	tests := []struct {
+		name  string
+		mode  MergeMode
+		steps []mergeStep
+	}{
+		{
+			name: "HTP takes the highest value of each channel",
+			mode: MergeHTP,
+			steps: []mergeStep{
+				{controllerA, 0, dmx.Universe{10, 200, 30, 0}, dmx.Universe{10, 200, 30, 0}, true},
+				{controllerB, 0, dmx.Universe{100, 20, 30, 40}, dmx.Universe{100, 200, 30, 40}, true},
+				{controllerA, 0, dmx.Universe{0, 0, 0, 0}, dmx.Universe{100, 20, 30, 40}, true},
+			},
+		},
+		{
+			name: "HTP outputs the longest frame",
+			mode: MergeHTP,
+			steps: []mergeStep{
+				{controllerA, 0, dmx.Universe{1, 2}, dmx.Universe{1, 2}, true},
+				{controllerB, 0, dmx.Universe{0, 0, 5, 6}, dmx.Universe{1, 2, 5, 6}, true},
+			},
+		},
+		{
+			name: "LTP takes each channel from the source which changed it last",
+			mode: MergeLTP,
+			steps: []mergeStep{
+				{controllerA, 0, dmx.Universe{10, 200, 30, 0}, dmx.Universe{10, 200, 30, 0}, true},
+				{controllerB, time.Second, dmx.Universe{1, 2, 3, 4}, dmx.Universe{1, 2, 3, 4}, true},
+				{controllerA, 2 * time.Second, dmx.Universe{10, 200, 99, 0}, dmx.Universe{1, 2, 99, 4}, true},
+				{controllerB, 3 * time.Second, dmx.Universe{1, 2, 3, 4}, dmx.Universe{1, 2, 99, 4}, true},
+				{controllerB, 4 * time.Second, dmx.Universe{5, 2, 3, 4}, dmx.Universe{5, 2, 99, 4}, true},
+			},
+		},
+		{
+			name: "LTP outputs the longest frame",
+			mode: MergeLTP,
+			steps: []mergeStep{
+				{controllerA, 0, dmx.Universe{1, 2, 3, 4}, dmx.Universe{1, 2, 3, 4}, true},
+				{controllerB, time.Second, dmx.Universe{7, 8}, dmx.Universe{7, 8, 3, 4}, true},
+				{controllerB, 2 * time.Second, dmx.Universe{7, 8, 9}, dmx.Universe{7, 8, 9, 4}, true},
+			},
+		},
+		{
+			name: "a third source is ignored",
+			mode: MergeHTP,
+			steps: []mergeStep{
+				{controllerA, 0, dmx.Universe{1, 0}, dmx.Universe{1, 0}, true},
+				{controllerB, 0, dmx.Universe{0, 2}, dmx.Universe{1, 2}, true},
+				{controllerC, 0, dmx.Universe{255, 255}, dmx.Universe{1, 2}, false},
+			},
+		},
+		{
+			name: "a silent source times out",
+			mode: MergeHTP,
+			steps: []mergeStep{
+				{controllerA, 0, dmx.Universe{200, 0}, dmx.Universe{200, 0}, true},
+				{controllerB, 5 * time.Second, dmx.Universe{0, 2}, dmx.Universe{200, 2}, true},
+				{controllerB, MergeTimeout + time.Second, dmx.Universe{0, 3}, dmx.Universe{0, 3}, true},
+			},
+		},
+		{
+			name: "a third source replaces one which timed out",
+			mode: MergeHTP,
+			steps: []mergeStep{
+				{controllerA, 0, dmx.Universe{1, 0}, dmx.Universe{1, 0}, true},
+				{controllerB, MergeTimeout, dmx.Universe{0, 2}, dmx.Universe{1, 2}, true},
+				{controllerC, MergeTimeout + time.Second, dmx.Universe{0, 0, 3}, dmx.Universe{0, 2, 3}, true},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		m := NewMerger(tt.mode)
+		for i, step := range tt.steps {
+			got, ok := m.Merge(step.from, NewDMX(mergeAddress, 0, step.data), mergeStart.Add(step.after))
+			if ok != step.accepted {
+				t.Errorf("%s: step %d accepted %v, want %v", tt.name, i, ok, step.accepted)
+			}
+			if !bytes.Equal(got, step.want) {
+				t.Errorf("%s: step %d merged %v, want %v", tt.name, i, got, step.want)
+			}
+		}
+	}
+}
+
+func TestMergerExpire(t *testing.T) {
+	m := NewMerger(MergeHTP)
+	m.Merge(controllerA, NewDMX(mergeAddress, 0, dmx.Universe{100, 0}), mergeStart)
+	m.Merge(controllerB, NewDMX(mergeAddress, 0, dmx.Universe{0, 50}), mergeStart.Add(5*time.Second))
+
+	m.Expire(mergeStart.Add(MergeTimeout + time.Second))
+	if got, want := m.Universe(mergeAddress), (dmx.Universe{0, 50}); !bytes.Equal(got, want) {
+		t.Errorf("after expiring one source, universe is %v, want %v", got, want)
+	}
+
+	m.Expire(mergeStart.Add(time.Minute))
+	if got := m.Universe(mergeAddress); len(got) != 0 {
+		t.Errorf("after expiring every source, universe is %v", got)
+	}
+	if got := m.Universe(Address(2)); got != nil {
+		t.Errorf("unused port has universe %v", got)
+	}
+}
+
+func TestMergerSetMode(t *testing.T) {
+	m := NewMerger(MergeHTP)
+	m.Merge(controllerA, NewDMX(mergeAddress, 0, dmx.Universe{100, 0, 7}), mergeStart)
+	m.Merge(controllerB, NewDMX(mergeAddress, 0, dmx.Universe{0, 50}), mergeStart.Add(time.Second))
+
+	m.SetMode(mergeAddress, MergeLTP)
+	if got, want := m.Universe(mergeAddress), (dmx.Universe{0, 50, 7}); !bytes.Equal(got, want) {
+		t.Errorf("after switching to LTP, universe is %v, want %v", got, want)
+	}
+
+	m.SetMode(mergeAddress, MergeHTP)
+	if got, want := m.Universe(mergeAddress), (dmx.Universe{100, 50, 7}); !bytes.Equal(got, want) {
+		t.Errorf("after switching to HTP, universe is %v, want %v", got, want)
+	}
+}
+
+func TestMergerStatus(t *testing.T) {
+	m := NewMerger(MergeHTP)
+
+	if got := m.Status(mergeAddress); got != 0 {
+		t.Errorf("status of an unused port is %08b", got)
+	}
+
+	m.Merge(controllerA, NewDMX(mergeAddress, 0, dmx.Universe{1, 2}), mergeStart)
+	if got, want := m.Status(mergeAddress), PortOutputTransmitting; got != want {
+		t.Errorf("status with one source is %08b, want %08b", got, want)
+	}
+
+	m.Merge(controllerB, NewDMX(mergeAddress, 0, dmx.Universe{1, 2}), mergeStart)
+	if got, want := m.Status(mergeAddress), PortOutputTransmitting|PortOutputMerging; got != want {
+		t.Errorf("status with two sources is %08b, want %08b", got, want)
+	}
+
+	m.SetMode(mergeAddress, MergeLTP)
+	if got, want := m.Status(mergeAddress), PortOutputTransmitting|PortOutputMerging|PortOutputMergeLTP; got != want {
+		t.Errorf("status merging LTP is %08b, want %08b", got, want)
+	}
+
+	m.Expire(mergeStart.Add(time.Minute))
+	if got, want := m.Status(mergeAddress), PortOutputMergeLTP; got != want {
+		t.Errorf("status after every source expired is %08b, want %08b", got, want)
+	}
+}
+
+func TestMergerCopies(t *testing.T) {
+	m := NewMerger(MergeHTP)
+	data := dmx.Universe{1, 2}
+
+	got, _ := m.Merge(controllerA, NewDMX(mergeAddress, 0, data), mergeStart)
+	got[0] = 99
+	data[1] = 99
+
+	if u := m.Universe(mergeAddress); !bytes.Equal(u, dmx.Universe{1, 2}) {
+		t.Errorf("merged universe changed to %v through a caller's slice", u)
+	}
+}
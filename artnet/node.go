@@ -11,6 +11,10 @@ type Node struct {
 
 	Style Style
 	MAC   net.HardwareAddr
+
+	OEM              uint16
+	ESTAManufacturer uint16
+	BindIndex        uint8
 }
 
 type NodePort struct {
@@ -19,3 +23,8 @@ type NodePort struct {
 	Input   PortInput
 	Output  PortOutput
 }
+
+// InputEnabled reports whether the port's input is enabled.
+func (p NodePort) InputEnabled() bool {
+	return !PortInputDisabled.Enabled(p.Input)
+}
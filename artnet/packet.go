@@ -1,6 +1,8 @@
 package artnet
 
 import (
+	"bytes"
+	"fmt"
 	"io"
 
 	"lyra.codes/blinken/artnet/wire"
@@ -10,3 +12,30 @@ type Packet interface {
 	Read(r wire.Reader) error
 	Write(w io.Writer) error
 }
+
+// ReadPacket decodes an Art-Net packet of any supported operation, in the
+// given parsing mode.
+func ReadPacket(data []byte, mode wire.Mode) (Packet, error) {
+	head := Header{}
+	if err := head.unmarshalBinary(data); err != nil {
+		return nil, err
+	}
+
+	p := newPacket(head)
+	if p == nil {
+		return nil, &wire.FieldError{Field: "OpCode", Err: fmt.Errorf("unsupported operation 0x%04x", uint16(head.Operation))}
+	}
+
+	if err := readPacket(p, data, mode); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// readPacket decodes a packet, using its fast path if it has one.
+func readPacket(p Packet, data []byte, mode wire.Mode) error {
+	if u, ok := p.(binaryUnmarshaler); ok {
+		return u.unmarshalBinary(data, mode)
+	}
+	return wire.DecodeMode(bytes.NewBuffer(data), p, mode)
+}
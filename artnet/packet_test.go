@@ -0,0 +1,123 @@
+package artnet
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"lyra.codes/blinken/artnet/wire"
+)
+
+func TestReadPacketStrict(t *testing.T) {
+	b, _ := NewDMX(1, 1, testUniverse()).MarshalBinary()
+
+	tests := map[string][]byte{
+		"truncated header": b[:HeaderLength-1],
+		"truncated data":   b[:len(b)-1],
+		"oversized":        append(b, 0),
+	}
+	for name, data := range tests {
+		_, err := ReadPacket(data, wire.Strict)
+		var ferr *wire.FieldError
+		if !errors.As(err, &ferr) {
+			t.Errorf("%s: ReadPacket error = %v, want a FieldError", name, err)
+		}
+	}
+}
+
+func TestReadPacketLength(t *testing.T) {
+	b, _ := NewDMX(1, 1, testUniverse()).MarshalBinary()
+	binary.BigEndian.PutUint16(b[16:], 513)
+
+	for _, mode := range []wire.Mode{wire.Strict, wire.Lenient} {
+		if _, err := ReadPacket(b, mode); err == nil {
+			t.Errorf("%s: ReadPacket accepted a DMX Length of 513", mode)
+		}
+	}
+}
+
+func TestReadPacketLenient(t *testing.T) {
+	b, _ := testPollReply().MarshalBinary()
+
+	// Art-Net II nodes send replies which end after Style.
+	short := b[:201]
+	if _, err := ReadPacket(short, wire.Strict); !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Errorf("strict ReadPacket error = %v, want %v", err, io.ErrUnexpectedEOF)
+	}
+
+	p, err := ReadPacket(short, wire.Lenient)
+	if err != nil {
+		t.Fatalf("lenient ReadPacket: %v", err)
+	}
+
+	reply := p.(*PollReply)
+	if reply.ShortName != "blinken" || reply.Style != StyleNode {
+		t.Errorf("lenient ReadPacket lost fields: %+v", reply)
+	}
+	if reply.BindIndex != 0 || reply.Status2 != 0 {
+		t.Errorf("lenient ReadPacket didn't default missing fields: %+v", reply)
+	}
+	if n := len(reply.ToNode().Ports); n != 2 {
+		t.Errorf("node has %d ports, want 2", n)
+	}
+}
+
+func TestWriteTextTooLong(t *testing.T) {
+	text := strings.Repeat("x", 600)
+	for _, p := range []Packet{NewDiagData(DPLow, 0, text), NewCommand(ESTAAll, Directive{Name: text})} {
+		if err := p.Write(io.Discard); err == nil {
+			t.Errorf("%T with %d characters of text was written", p, len(text))
+		}
+	}
+}
+
+func TestReadDMXLength(t *testing.T) {
+	tests := []struct {
+		name   string
+		data   []byte
+		length uint16
+		strict bool
+	}{
+		{"even", []byte{1, 2}, 2, true},
+		{"empty", nil, 0, false},
+		{"odd", []byte{1, 2, 3}, 3, false},
+	}
+
+	for _, tt := range tests {
+		b, _ := NewDMX(1, 1, tt.data).MarshalBinary()
+
+		_, err := ReadPacket(b, wire.Strict)
+		if ok := err == nil; ok != tt.strict {
+			t.Errorf("%s: strict ReadPacket error = %v", tt.name, err)
+		}
+		p, err := ReadPacket(b, wire.Lenient)
+		if err != nil {
+			t.Errorf("%s: lenient ReadPacket: %v", tt.name, err)
+			continue
+		}
+		if got := p.(*DMX).Length; got != tt.length {
+			t.Errorf("%s: Length is %d, want %d", tt.name, got, tt.length)
+		}
+
+		d := &DMX{}
+		if err := d.Read(bytes.NewBuffer(b)); (err == nil) != tt.strict {
+			t.Errorf("%s: Read error = %v", tt.name, err)
+		}
+	}
+}
+
+func TestReadDMXShort(t *testing.T) {
+	b, _ := NewDMX(1, 1, testUniverse()).MarshalBinary()
+	short := b[:len(b)-12]
+
+	p, err := ReadPacket(short, wire.Lenient)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d := p.(*DMX); int(d.Length) != len(d.Data) || len(d.Data) != 500 {
+		t.Errorf("short DMX has Length %d and %d channels, want 500 of both", d.Length, len(d.Data))
+	}
+}
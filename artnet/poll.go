@@ -2,7 +2,7 @@ package artnet
 
 import (
 	"encoding/binary"
-	"errors"
+	"fmt"
 	"io"
 	"net"
 
@@ -57,24 +57,48 @@ func PollDiagnostics(priority DiagnosticPriority, broadcast bool) PollOption {
 }
 
 func (p *Poll) Read(r wire.Reader) error {
-	p.Header.Read(r)
+	return wire.Decode(r, p)
+}
+
+func (p *Poll) Write(w io.Writer) error {
+	return wire.Encode(w, p)
+}
 
-	parser := wire.Parse(r)
-	p.Version = Version(parser.Int16("Version", binary.BigEndian))
-	p.TalkToMe = TalkToMe(parser.Int8("TalkToMe"))
-	p.Priority = DiagnosticPriority(parser.Int8("Priority"))
+// pollLength is the length of a Poll message.
+const pollLength = HeaderLength + 4
 
-	return parser.Err()
+// MarshalBinary returns the encoded message.
+func (p *Poll) MarshalBinary() ([]byte, error) {
+	return p.AppendBinary(nil)
 }
 
-func (p *Poll) Write(w io.Writer) error {
-	p.Header.Write(w)
+// AppendBinary appends the encoded message to dst.
+func (p *Poll) AppendBinary(dst []byte) ([]byte, error) {
+	dst = p.Header.appendBinary(dst)
+	dst = appendUint16(dst, uint16(p.Version), binary.BigEndian)
+	return append(dst, byte(p.TalkToMe), byte(p.Priority)), nil
+}
+
+// UnmarshalBinary strictly decodes a message.
+func (p *Poll) UnmarshalBinary(data []byte) error {
+	return p.unmarshalBinary(data, wire.Strict)
+}
+
+func (p *Poll) unmarshalBinary(data []byte, mode wire.Mode) error {
+	if err := p.Header.unmarshalBinary(data); err != nil {
+		return err
+	}
 
-	return wire.Build(w).
-		Int16("Version", uint16(p.Version), binary.BigEndian).
-		Int8("TalkToMe", uint8(p.TalkToMe)).
-		Int8("Priority", uint8(p.Priority)).
-		Err()
+	var buf [pollLength]byte
+	data, err := fixedLength("Poll", data, pollLength, mode, buf[:])
+	if err != nil {
+		return err
+	}
+
+	p.Version = Version(binary.BigEndian.Uint16(data[10:]))
+	p.TalkToMe = TalkToMe(data[12])
+	p.Priority = DiagnosticPriority(data[13])
+	return nil
 }
 
 // TalkToMe is the Node behavior options that can be sent in a Poll operation.
@@ -114,7 +138,7 @@ const (
 // PollReply is a reponse to a Poll message from an Art-Net device.
 type PollReply struct {
 	Header
-	Node            net.UDPAddr
+	Node            net.UDPAddr `wire:"le"`
 	FirmwareVersion uint16
 
 	NetSwitch uint8
@@ -124,22 +148,23 @@ type PollReply struct {
 	UBEAVersion uint8
 
 	Status1          uint8
-	ESTAManufacturer uint16
+	ESTAManufacturer uint16 `wire:"le"`
 
-	ShortName  string
-	LongName   string
-	NodeReport string
+	ShortName  string `wire:"size=18"`
+	LongName   string `wire:"size=64"`
+	NodeReport string `wire:"size=64"`
 
 	PortCount       uint16
-	PortTypes       []PortType
-	PortInputs      []PortInput
-	PortOutputs     []PortOutput
-	InputUniverses  []uint8
-	OutputUniverses []uint8
+	PortTypes       []PortType   `wire:"size=4"`
+	PortInputs      []PortInput  `wire:"size=4"`
+	PortOutputs     []PortOutput `wire:"size=4"`
+	InputUniverses  []uint8      `wire:"size=4"`
+	OutputUniverses []uint8      `wire:"size=4"`
 
 	Video  uint8
 	Macro  uint8
 	Remote uint8
+	_      [3]byte `wire:"skip"`
 
 	Style     Style
 	MAC       net.HardwareAddr
@@ -147,14 +172,46 @@ type PollReply struct {
 	BindIndex uint8
 
 	Status2 uint8
+	_       [26]byte `wire:"skip"`
 }
 
 type PortType uint8
 
+// PortInput is the input status of a node's port.
 type PortInput uint8
 
+const (
+	PortInputReceiving   PortInput = 0x80
+	PortInputTestPackets PortInput = 0x40
+	PortInputSIP         PortInput = 0x20
+	PortInputText        PortInput = 0x10
+	PortInputDisabled    PortInput = 0x08
+	PortInputErrors      PortInput = 0x04
+	PortInputSACN        PortInput = 0x01
+)
+
+func (f PortInput) Enabled(v PortInput) bool {
+	return (v & f) != 0
+}
+
+// PortOutput is the output status of a node's port.
 type PortOutput uint8
 
+const (
+	PortOutputTransmitting PortOutput = 0x80
+	PortOutputTestPackets  PortOutput = 0x40
+	PortOutputSIP          PortOutput = 0x20
+	PortOutputText         PortOutput = 0x10
+	PortOutputMerging      PortOutput = 0x08
+	PortOutputShorted      PortOutput = 0x04
+	PortOutputMergeLTP     PortOutput = 0x02
+	PortOutputSACN         PortOutput = 0x01
+)
+
+func (f PortOutput) Enabled(v PortOutput) bool {
+	return (v & f) != 0
+}
+
 func (p *PollReply) ToNode() *Node {
 	return &Node{
 		NetworkAddress: &p.Node,
@@ -163,11 +220,21 @@ func (p *PollReply) ToNode() *Node {
 		LongName:       p.LongName,
 		Style:          p.Style,
 		MAC:            p.MAC,
+
+		OEM:              p.OEM,
+		ESTAManufacturer: p.ESTAManufacturer,
+		BindIndex:        p.BindIndex,
 	}
 }
 
 func (p *PollReply) Ports() []NodePort {
+	// Don't trust PortCount beyond the ports the reply describes.
 	count := int(p.PortCount)
+	for _, n := range []int{len(p.PortTypes), len(p.PortInputs), len(p.PortOutputs), len(p.OutputUniverses)} {
+		if n < count {
+			count = n
+		}
+	}
 	ports := make([]NodePort, 0, count)
 
 	for i := 0; i < count; i++ {
@@ -184,65 +251,146 @@ func (p *PollReply) Ports() []NodePort {
 }
 
 func (p *PollReply) Read(r wire.Reader) error {
-	p.Header.Read(r)
-
-	parser := wire.Parse(r)
-	p.Node = net.UDPAddr{
-		IP:   parser.IPv4("Node.IP"),
-		Port: int(parser.Int16("Node.Port", binary.LittleEndian)),
-	}
-	p.FirmwareVersion = parser.Int16("FirmwareVersion", binary.BigEndian)
+	return wire.Decode(r, p)
+}
 
-	p.NetSwitch = parser.Int8("NetSwitch")
-	p.SubSwitch = parser.Int8("SubSwitch")
+func (p *PollReply) Write(w io.Writer) error {
+	return wire.Encode(w, p)
+}
 
-	p.OEM = parser.Int16("OEM", binary.BigEndian)
-	p.UBEAVersion = parser.Int8("UBEAVersion")
+const (
+	// pollReplyLength is the length of a PollReply message.
+	pollReplyLength = 239
 
-	p.Status1 = parser.Int8("Status1")
-	p.ESTAManufacturer = parser.Int16("ESTAManufacturer", binary.BigEndian)
+	// pollReplyPorts is the number of ports described by a PollReply.
+	pollReplyPorts = 4
+)
 
-	p.ShortName = parser.String("ShortName", 18)
-	p.LongName = parser.String("LongName", 64)
-	p.NodeReport = parser.String("NodeReport", 64)
+// MarshalBinary returns the encoded message.
+func (p *PollReply) MarshalBinary() ([]byte, error) {
+	return p.AppendBinary(nil)
+}
 
-	p.PortCount = parser.Int16("PortCount", binary.BigEndian)
-	p.PortTypes = make([]PortType, 4)
-	for i := 0; i < 4; i++ {
-		p.PortTypes[i] = PortType(parser.Int8("PortTypes"))
+// AppendBinary appends the encoded message to dst.
+func (p *PollReply) AppendBinary(dst []byte) ([]byte, error) {
+	var ports [5][pollReplyPorts]byte
+	for i, n := range []int{len(p.PortTypes), len(p.PortInputs), len(p.PortOutputs), len(p.InputUniverses), len(p.OutputUniverses)} {
+		if n > pollReplyPorts {
+			return dst, &wire.FieldError{Field: pollReplyPortFields[i], Err: fmt.Errorf("%d elements is more than %d", n, pollReplyPorts)}
+		}
 	}
-	p.PortInputs = make([]PortInput, 4)
-	for i := 0; i < 4; i++ {
-		p.PortInputs[i] = PortInput(parser.Int8("PortInputs"))
+	for i, v := range p.PortTypes {
+		ports[0][i] = byte(v)
 	}
-	p.PortOutputs = make([]PortOutput, 4)
-	for i := 0; i < 4; i++ {
-		p.PortOutputs[i] = PortOutput(parser.Int8("PortOutputs"))
+	for i, v := range p.PortInputs {
+		ports[1][i] = byte(v)
 	}
-	p.InputUniverses = make([]uint8, 4)
-	for i := 0; i < 4; i++ {
-		p.InputUniverses[i] = parser.Int8("InputUniverses")
+	for i, v := range p.PortOutputs {
+		ports[2][i] = byte(v)
 	}
-	p.OutputUniverses = make([]uint8, 4)
-	for i := 0; i < 4; i++ {
-		p.OutputUniverses[i] = parser.Int8("OutputUniverses")
+	copy(ports[3][:], p.InputUniverses)
+	copy(ports[4][:], p.OutputUniverses)
+
+	if len(p.MAC) != 6 {
+		return dst, &wire.FieldError{Field: "MAC", Err: fmt.Errorf("MAC %q is not 6 bytes", p.MAC)}
 	}
 
-	p.Video = parser.Int8("Video")
-	p.Macro = parser.Int8("Macro")
-	p.Remote = parser.Int8("Remote")
+	var err error
+	dst = p.Header.appendBinary(dst)
+	if dst, err = appendIPv4(dst, "Node.IP", p.Node.IP); err != nil {
+		return dst, err
+	}
+	dst = appendUint16(dst, uint16(p.Node.Port), binary.LittleEndian)
+	dst = appendUint16(dst, p.FirmwareVersion, binary.BigEndian)
+	dst = append(dst, p.NetSwitch, p.SubSwitch)
+	dst = appendUint16(dst, p.OEM, binary.BigEndian)
+	dst = append(dst, p.UBEAVersion, p.Status1)
+	dst = appendUint16(dst, p.ESTAManufacturer, binary.LittleEndian)
+	if dst, err = appendString(dst, "ShortName", p.ShortName, 18); err != nil {
+		return dst, err
+	}
+	if dst, err = appendString(dst, "LongName", p.LongName, 64); err != nil {
+		return dst, err
+	}
+	if dst, err = appendString(dst, "NodeReport", p.NodeReport, 64); err != nil {
+		return dst, err
+	}
+	dst = appendUint16(dst, p.PortCount, binary.BigEndian)
+	for i := range ports {
+		dst = append(dst, ports[i][:]...)
+	}
+	dst = append(dst, p.Video, p.Macro, p.Remote, 0, 0, 0, byte(p.Style))
+	dst = append(dst, p.MAC...)
+	if dst, err = appendIPv4(dst, "BindIP", p.BindIP); err != nil {
+		return dst, err
+	}
+	dst = append(dst, p.BindIndex, p.Status2)
+	dst = append(dst, make([]byte, 26)...)
 
-	parser.Skip("Spare", 3)
-	p.Style = Style(parser.Int8("Style"))
-	p.MAC = parser.MAC("MAC")
-	p.BindIP = parser.IPv4("BindIP")
-	p.BindIndex = parser.Int8("BindIndex")
+	return dst, nil
+}
 
-	p.Status2 = parser.Int8("Status2")
+var pollReplyPortFields = []string{"PortTypes", "PortInputs", "PortOutputs", "InputUniverses", "OutputUniverses"}
 
-	return parser.Err()
+// UnmarshalBinary strictly decodes a message, reusing the memory of p's
+// slices and strings where it can. Slices from p must not be retained
+// elsewhere, as they are by ToNode.
+func (p *PollReply) UnmarshalBinary(data []byte) error {
+	return p.unmarshalBinary(data, wire.Strict)
 }
 
-func (p *PollReply) Write(w io.Writer) error {
-	return errors.New("PollReply.Write() not implemented")
+func (p *PollReply) unmarshalBinary(data []byte, mode wire.Mode) error {
+	if err := p.Header.unmarshalBinary(data); err != nil {
+		return err
+	}
+
+	// Older nodes send shorter replies, without the fields added since.
+	var buf [pollReplyLength]byte
+	data, err := fixedLength("PollReply", data, pollReplyLength, mode, buf[:])
+	if err != nil {
+		return err
+	}
+
+	p.Node.IP = append(p.Node.IP[:0], data[10:14]...)
+	p.Node.Port = int(binary.LittleEndian.Uint16(data[14:]))
+	p.FirmwareVersion = binary.BigEndian.Uint16(data[16:])
+	p.NetSwitch = data[18]
+	p.SubSwitch = data[19]
+	p.OEM = binary.BigEndian.Uint16(data[20:])
+	p.UBEAVersion = data[22]
+	p.Status1 = data[23]
+	p.ESTAManufacturer = binary.LittleEndian.Uint16(data[24:])
+
+	if p.ShortName, err = unmarshalString("ShortName", data[26:44], p.ShortName, mode); err != nil {
+		return err
+	}
+	if p.LongName, err = unmarshalString("LongName", data[44:108], p.LongName, mode); err != nil {
+		return err
+	}
+	if p.NodeReport, err = unmarshalString("NodeReport", data[108:172], p.NodeReport, mode); err != nil {
+		return err
+	}
+
+	p.PortCount = binary.BigEndian.Uint16(data[172:])
+	p.PortTypes = p.PortTypes[:0]
+	p.PortInputs = p.PortInputs[:0]
+	p.PortOutputs = p.PortOutputs[:0]
+	for i := 0; i < pollReplyPorts; i++ {
+		p.PortTypes = append(p.PortTypes, PortType(data[174+i]))
+		p.PortInputs = append(p.PortInputs, PortInput(data[178+i]))
+		p.PortOutputs = append(p.PortOutputs, PortOutput(data[182+i]))
+	}
+	p.InputUniverses = append(p.InputUniverses[:0], data[186:190]...)
+	p.OutputUniverses = append(p.OutputUniverses[:0], data[190:194]...)
+
+	p.Video = data[194]
+	p.Macro = data[195]
+	p.Remote = data[196]
+	p.Style = Style(data[200])
+	p.MAC = append(p.MAC[:0], data[201:207]...)
+	p.BindIP = append(p.BindIP[:0], data[207:211]...)
+	p.BindIndex = data[211]
+	p.Status2 = data[212]
+
+	return nil
 }
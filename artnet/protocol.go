@@ -3,10 +3,11 @@ package artnet
 import (
 	"bytes"
 	"encoding/binary"
-	"errors"
 	"fmt"
 	"io"
 	"net"
+
+	"lyra.codes/blinken/artnet/wire"
 )
 
 // Port is the standard UDP port in the Art-Net specification.
@@ -32,7 +33,7 @@ const Version14 Version = 14
 
 func ReadVersion(r io.Reader) (Version, error) {
 	var op uint16
-	if err := binary.Read(r, binary.BigEndian, op); err != nil {
+	if err := binary.Read(r, binary.BigEndian, &op); err != nil {
 		return Version(0), err
 	}
 
@@ -55,7 +56,7 @@ type Header struct {
 
 func (h *Header) Read(r io.Reader) error {
 	var magic = make([]byte, len(Magic))
-	if _, err := r.Read(magic); err != nil {
+	if _, err := io.ReadFull(r, magic); err != nil {
 		return err
 	}
 
@@ -79,6 +80,23 @@ func (h Header) Write(w io.Writer) error {
 	return h.Operation.Write(w)
 }
 
+func (h *Header) DecodeWire(p *wire.Parser) {
+	// The header is required even when parsing leniently.
+	if magic := p.Bytes("ID", len(Magic)); !bytes.Equal(Magic, magic) {
+		p.Fail("ID", fmt.Errorf("received invalid magic %q", magic))
+	}
+	op := p.Bytes("OpCode", 2)
+	if len(op) < 2 {
+		p.Fail("OpCode", io.ErrUnexpectedEOF)
+		return
+	}
+	h.Operation = Operation(binary.LittleEndian.Uint16(op))
+}
+
+func (h *Header) EncodeWire(b *wire.Builder) {
+	b.Bytes("ID", Magic).Int16("OpCode", uint16(h.Operation), binary.LittleEndian)
+}
+
 // Operation is an Art-Net op-code.
 type Operation uint16
 
@@ -115,6 +133,12 @@ const (
 	OpDeviceTableControl Operation = 0x8200
 	OpRDM                Operation = 0x8300
 	OpRDMSub             Operation = 0x8400
+	OpTimeCode           Operation = 0x9700
+	OpTrigger            Operation = 0x9900
+	OpFirmwareMaster     Operation = 0xF200
+	OpFirmwareReply      Operation = 0xF300
+	OpIpProg             Operation = 0xF800
+	OpIpProgReply        Operation = 0xF900
 )
 
 // Style gives the type of participant in an Art-Net network.
@@ -129,17 +153,3 @@ const (
 	StyleConfig     Style = 0x05
 	StyleVisual     Style = 0x06
 )
-
-func readString(r io.Reader, capacity int) (string, error) {
-	d := make([]byte, capacity)
-	if _, err := r.Read(d); err != nil {
-		return "", err
-	}
-
-	end := bytes.IndexByte(d, 0)
-	if end < 0 {
-		return "", errors.New("terminating NUL not found")
-	}
-
-	return string(d[:end]), nil
-}
@@ -0,0 +1,118 @@
+package artnet
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+
+	"lyra.codes/blinken/artnet/wire"
+	"lyra.codes/blinken/rdm"
+)
+
+var (
+	rdmHeader = Header{Operation: OpRDM}
+)
+
+// RDMCommand is the command in an RDM message.
+type RDMCommand uint8
+
+const (
+	RDMProcess RDMCommand = 0x00
+)
+
+// RDM is the contents of an OpRDM message, which carries an E1.20 RDM
+// message to or from a device behind a node.
+type RDM struct {
+	Header
+	Version    Version
+	RDMVersion uint8
+	_          [8]byte `wire:"skip"`
+	Net        uint8
+	Command    RDMCommand
+	SubUni     uint8
+
+	// Data is the RDM message, without its start code.
+	Data []byte `wire:"rest"`
+}
+
+// NewRDM creates an RDM operation carrying a message for a port-address.
+func NewRDM(addr Address, m *rdm.Message) (*RDM, error) {
+	buf := bytes.Buffer{}
+	if err := m.Write(&buf); err != nil {
+		return nil, err
+	}
+
+	return &RDM{
+		Header:     rdmHeader,
+		Version:    Version14,
+		RDMVersion: RDMVersion,
+		Net:        addr.Net(),
+		Command:    RDMProcess,
+		SubUni:     uint8(addr),
+		Data:       buf.Bytes()[1:],
+	}, nil
+}
+
+// PortAddress returns the port-address of the device the message is for.
+func (p *RDM) PortAddress() Address {
+	return Address(p.Net)<<8 | Address(p.SubUni)
+}
+
+// Message decodes the RDM message carried by the operation.
+func (p *RDM) Message() (*rdm.Message, error) {
+	m := &rdm.Message{}
+	buf := bytes.NewBuffer(append([]byte{rdm.StartCode}, p.Data...))
+	if err := m.Read(buf); err != nil {
+		return nil, err
+	}
+
+	return m, nil
+}
+
+func (p *RDM) Read(r wire.Reader) error {
+	return wire.Decode(r, p)
+}
+
+func (p *RDM) Write(w io.Writer) error {
+	return wire.Encode(w, p)
+}
+
+// RequestRDM sends an RDM request to a device behind a node, and waits for
+// the device's response.
+func RequestRDM(ctx context.Context, t Transport, node *Node, addr Address, req *rdm.Message) (*rdm.Message, error) {
+	p, err := NewRDM(addr, req)
+	if err != nil {
+		return nil, err
+	}
+
+	sub := t.Subscribe(OpRDM)
+	defer sub.Close()
+
+	if err := t.Send(node.NetworkAddress, p); err != nil {
+		return nil, err
+	}
+
+	for {
+		select {
+		case msg, ok := <-sub.C:
+			if !ok {
+				return nil, fmt.Errorf("transport closed")
+			}
+
+			reply := msg.Packet.(*RDM)
+			if reply.PortAddress() != addr || !msg.From.IP.Equal(node.NetworkAddress.IP) {
+				continue
+			}
+
+			m, err := reply.Message()
+			if err != nil || !m.Responds(req) {
+				continue
+			}
+
+			return m, m.Err()
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
@@ -0,0 +1,224 @@
+package artnet
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"lyra.codes/blinken/artnet/wire"
+	"lyra.codes/blinken/rdm"
+)
+
+// specDir holds packets constructed field by field from the Art-Net
+// specification, one per file, as hex with comments. They check the layout
+// of every packet, but not how real nodes fill one in, since no captures
+// from real nodes are checked in.
+const specDir = "testdata/spec"
+
+// specPackets lists every packet in specDir, with the mode it can be parsed
+// in and a check of its fields.
+var specPackets = []struct {
+	name  string
+	mode  wire.Mode
+	check func(t *testing.T, p Packet)
+}{
+	{"poll", wire.Strict, func(t *testing.T, p Packet) {
+		poll := p.(*Poll)
+		expect(t, "TalkToMe", poll.TalkToMe, TalkToMeSendDiagnostics|TalkToMeBroadcastDiagnostics)
+		expect(t, "Priority", poll.Priority, DPHigh)
+	}},
+	{"poll-artnet4", wire.Lenient, func(t *testing.T, p Packet) {
+		expect(t, "Priority", p.(*Poll).Priority, DPLow)
+	}},
+	{"pollreply", wire.Strict, func(t *testing.T, p Packet) {
+		reply := p.(*PollReply)
+		expect(t, "ESTAManufacturer", reply.ESTAManufacturer, uint16(0x7a70))
+		expect(t, "BindIndex", reply.BindIndex, uint8(1))
+
+		node := reply.ToNode()
+		expect(t, "NetworkAddress", node.NetworkAddress.String(), "2.0.0.10:6454")
+		expect(t, "ShortName", node.ShortName, "blinken node")
+		expect(t, "MAC", node.MAC.String(), "02:00:00:00:00:0a")
+		expect(t, "len(Ports)", len(node.Ports), 2)
+		expect(t, "Ports[1].Address", node.Ports[1].Address, Address(0x0101))
+	}},
+	{"pollreply-short", wire.Lenient, func(t *testing.T, p Packet) {
+		reply := p.(*PollReply)
+		expect(t, "LongName", reply.LongName, "blinken two-port test node")
+		expect(t, "MAC", reply.MAC.String(), "02:00:00:00:00:0a")
+		expect(t, "BindIndex", reply.BindIndex, uint8(0))
+	}},
+	{"dmx", wire.Strict, func(t *testing.T, p Packet) {
+		d := p.(*DMX)
+		expect(t, "Sequence", d.Sequence, uint8(42))
+		expect(t, "Address", d.Address.String(), "1:0.1")
+		expect(t, "len(Data)", len(d.Data), 512)
+		expect(t, "Data[300]", d.Data[300], uint8(300%256))
+	}},
+	{"dmx-short", wire.Strict, func(t *testing.T, p Packet) {
+		d := p.(*DMX)
+		expect(t, "Input", d.Input, uint8(1))
+		expect(t, "len(Data)", len(d.Data), 24)
+	}},
+	{"sync", wire.Strict, func(t *testing.T, p Packet) {
+		expect(t, "Version", p.(*Sync).Version, Version14)
+	}},
+	{"diagdata", wire.Strict, func(t *testing.T, p Packet) {
+		d := p.(*DiagData)
+		expect(t, "Priority", d.Priority, DPHigh)
+		expect(t, "Text", d.Text, "Output 1 merging")
+	}},
+	{"command", wire.Strict, func(t *testing.T, p Packet) {
+		c := p.(*Command)
+		expect(t, "ESTAManufacturer", c.ESTAManufacturer, ESTAAll)
+		expect(t, "Directives", fmt.Sprint(c.Directives()), fmt.Sprint([]Directive{SwoutText("Playback")}))
+	}},
+	{"timecode", wire.Strict, func(t *testing.T, p Packet) {
+		expect(t, "String", p.(*TimeCode).String(), "01:02:03;04")
+	}},
+	{"trigger", wire.Strict, func(t *testing.T, p Packet) {
+		tr := p.(*Trigger)
+		expect(t, "Standard", tr.Standard(), true)
+		expect(t, "Key", tr.Key, KeyMacro)
+		expect(t, "SubKey", tr.SubKey, uint8(5))
+	}},
+	{"input", wire.Strict, func(t *testing.T, p Packet) {
+		in := p.(*Input)
+		expect(t, "Inputs[0]", in.Inputs[0], InputFlags(0))
+		expect(t, "Inputs[1]", in.Inputs[1], InputDisable)
+	}},
+	{"todrequest", wire.Strict, func(t *testing.T, p Packet) {
+		req := p.(*TodRequest)
+		expect(t, "Net", req.Net, uint8(1))
+		expect(t, "Addresses", string(req.Addresses), "\x01\x02")
+	}},
+	{"toddata", wire.Strict, func(t *testing.T, p Packet) {
+		d := p.(*TodData)
+		expect(t, "PortAddress", d.PortAddress().String(), "1:0.1")
+		expect(t, "len(UIDs)", len(d.UIDs), 2)
+		expect(t, "UIDs[1]", d.UIDs[1].String(), "7a70:00000002")
+	}},
+	{"todcontrol", wire.Strict, func(t *testing.T, p Packet) {
+		c := p.(*TodControl)
+		expect(t, "Command", c.Command, TodControlFlush)
+		expect(t, "PortAddress", c.PortAddress().String(), "1:0.1")
+	}},
+	{"rdm", wire.Strict, func(t *testing.T, p Packet) {
+		m, err := p.(*RDM).Message()
+		if err != nil {
+			t.Fatalf("Message: %v", err)
+		}
+		expect(t, "CommandClass", m.CommandClass, rdm.GetCommand)
+		expect(t, "PID", m.PID, rdm.PIDDeviceInfo)
+		expect(t, "Destination", m.Destination.String(), "7a70:00000001")
+	}},
+	{"firmwaremaster", wire.Strict, func(t *testing.T, p Packet) {
+		fw := p.(*FirmwareMaster)
+		expect(t, "Type", fw.Type, FirmFirst)
+		expect(t, "Length", fw.Length, uint32(768))
+		expect(t, "len(Data)", len(fw.Data), FirmwareBlockSize)
+	}},
+	{"firmwarereply", wire.Strict, func(t *testing.T, p Packet) {
+		expect(t, "Type", p.(*FirmwareReply).Type, FirmBlockGood)
+	}},
+	{"ipprog", wire.Strict, func(t *testing.T, p Packet) {
+		prog := p.(*IpProg)
+		expect(t, "IP", prog.IP.String(), "2.0.0.20")
+		expect(t, "Mask", prog.Mask.String(), "255.0.0.0")
+	}},
+	{"ipprogreply", wire.Strict, func(t *testing.T, p Packet) {
+		reply := p.(*IpProgReply)
+		expect(t, "IP", reply.IP.String(), "2.0.0.20")
+		expect(t, "Gateway", reply.Gateway.String(), "2.0.0.1")
+	}},
+}
+
+func expect(t *testing.T, field string, got, want interface{}) {
+	t.Helper()
+	if got != want {
+		t.Errorf("%s = %v, want %v", field, got, want)
+	}
+}
+
+// readSpec reads a packet from specDir.
+func readSpec(tb testing.TB, name string) []byte {
+	tb.Helper()
+
+	path := filepath.Join(specDir, name+".hex")
+	text, err := os.ReadFile(path)
+	if err != nil {
+		tb.Fatal(err)
+	}
+
+	var data []byte
+	for _, line := range strings.Split(string(text), "\n") {
+		if i := strings.IndexByte(line, '#'); i >= 0 {
+			line = line[:i]
+		}
+
+		b, err := hex.DecodeString(strings.Join(strings.Fields(line), ""))
+		if err != nil {
+			tb.Fatalf("%s: %v", path, err)
+		}
+		data = append(data, b...)
+	}
+
+	return data
+}
+
+func encode(p Packet) ([]byte, error) {
+	buf := bytes.Buffer{}
+	err := p.Write(&buf)
+	return buf.Bytes(), err
+}
+
+func TestSpecPackets(t *testing.T) {
+	for _, g := range specPackets {
+		g := g
+		t.Run(g.name, func(t *testing.T) {
+			data := readSpec(t, g.name)
+
+			p, err := ReadPacket(data, g.mode)
+			if err != nil {
+				t.Fatalf("ReadPacket(%s): %v", g.mode, err)
+			}
+			g.check(t, p)
+
+			if g.mode == wire.Lenient {
+				if _, err := ReadPacket(data, wire.Strict); err == nil {
+					t.Error("strict ReadPacket accepted a packet expected to need lenient parsing")
+				}
+				return
+			}
+
+			b, err := encode(p)
+			if err != nil {
+				t.Fatalf("Write: %v", err)
+			}
+			if !bytes.Equal(b, data) {
+				t.Errorf("Write = %x, want %x", b, data)
+			}
+		})
+	}
+}
+
+func TestSpecPacketsListed(t *testing.T) {
+	files, err := filepath.Glob(filepath.Join(specDir, "*.hex"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	listed := make(map[string]bool)
+	for _, g := range specPackets {
+		listed[g.name] = true
+	}
+	for _, f := range files {
+		if name := strings.TrimSuffix(filepath.Base(f), ".hex"); !listed[name] {
+			t.Errorf("%s is not listed in specPackets", f)
+		}
+	}
+}
@@ -0,0 +1,74 @@
+package artnet
+
+import (
+	"encoding/binary"
+	"io"
+
+	"lyra.codes/blinken/artnet/wire"
+)
+
+var (
+	syncHeader = Header{Operation: OpSync}
+)
+
+// Sync is the contents of an OpSync message, which tells nodes to output
+// the DMX data they have buffered since the last Sync.
+type Sync struct {
+	Header
+	Version Version
+	Aux1    uint8
+	Aux2    uint8
+}
+
+// NewSync creates a new Sync operation.
+func NewSync() *Sync {
+	return &Sync{
+		Header:  syncHeader,
+		Version: Version14,
+	}
+}
+
+func (p *Sync) Read(r wire.Reader) error {
+	return wire.Decode(r, p)
+}
+
+func (p *Sync) Write(w io.Writer) error {
+	return wire.Encode(w, p)
+}
+
+// syncLength is the length of a Sync message.
+const syncLength = HeaderLength + 4
+
+// MarshalBinary returns the encoded message.
+func (p *Sync) MarshalBinary() ([]byte, error) {
+	return p.AppendBinary(nil)
+}
+
+// AppendBinary appends the encoded message to dst.
+func (p *Sync) AppendBinary(dst []byte) ([]byte, error) {
+	dst = p.Header.appendBinary(dst)
+	dst = appendUint16(dst, uint16(p.Version), binary.BigEndian)
+	return append(dst, p.Aux1, p.Aux2), nil
+}
+
+// UnmarshalBinary strictly decodes a message.
+func (p *Sync) UnmarshalBinary(data []byte) error {
+	return p.unmarshalBinary(data, wire.Strict)
+}
+
+func (p *Sync) unmarshalBinary(data []byte, mode wire.Mode) error {
+	if err := p.Header.unmarshalBinary(data); err != nil {
+		return err
+	}
+
+	var buf [syncLength]byte
+	data, err := fixedLength("Sync", data, syncLength, mode, buf[:])
+	if err != nil {
+		return err
+	}
+
+	p.Version = Version(binary.BigEndian.Uint16(data[10:]))
+	p.Aux1 = data[12]
+	p.Aux2 = data[13]
+	return nil
+}
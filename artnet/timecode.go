@@ -0,0 +1,218 @@
+package artnet
+
+import (
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"lyra.codes/blinken/artnet/wire"
+)
+
+var (
+	timeCodeHeader = Header{Operation: OpTimeCode}
+)
+
+// TimeCodeType is the frame rate of a TimeCode.
+type TimeCodeType uint8
+
+const (
+	TimeCodeFilm  TimeCodeType = 0 // 24 fps
+	TimeCodeEBU   TimeCodeType = 1 // 25 fps
+	TimeCodeDF    TimeCodeType = 2 // 29.97 fps drop-frame
+	TimeCodeSMPTE TimeCodeType = 3 // 30 fps
+)
+
+// FramesPerSecond returns the nominal number of frames in each second.
+func (t TimeCodeType) FramesPerSecond() int {
+	switch t {
+	case TimeCodeFilm:
+		return 24
+	case TimeCodeEBU:
+		return 25
+	default:
+		return 30
+	}
+}
+
+// frameDuration returns the real duration of one frame.
+func (t TimeCodeType) frameDuration() time.Duration {
+	if t == TimeCodeDF {
+		return time.Second * 1001 / 30000
+	}
+	return time.Second / time.Duration(t.FramesPerSecond())
+}
+
+func (t TimeCodeType) String() string {
+	switch t {
+	case TimeCodeFilm:
+		return "Film"
+	case TimeCodeEBU:
+		return "EBU"
+	case TimeCodeDF:
+		return "DF"
+	case TimeCodeSMPTE:
+		return "SMPTE"
+	default:
+		return fmt.Sprintf("TimeCodeType(%d)", uint8(t))
+	}
+}
+
+const (
+	// Drop-frame timecode skips frames 0 and 1 at the start of each minute,
+	// except every tenth minute.
+	dfFramesPerMinute    = 30*60 - 2
+	dfFramesPer10Minutes = 10*dfFramesPerMinute + 2
+)
+
+// TimeCode is the contents of an OpTimeCode message.
+type TimeCode struct {
+	Header
+	Version  Version
+	_        [1]byte `wire:"skip"`
+	StreamID uint8
+	Frames   uint8
+	Seconds  uint8
+	Minutes  uint8
+	Hours    uint8
+	Type     TimeCodeType
+}
+
+// NewTimeCode creates a TimeCode operation for a position in a show.
+func NewTimeCode(position time.Duration, typ TimeCodeType) *TimeCode {
+	p := &TimeCode{
+		Header:  timeCodeHeader,
+		Version: Version14,
+		Type:    typ,
+	}
+	p.SetDuration(position)
+
+	return p
+}
+
+// Frame returns the number of frames since 00:00:00:00.
+func (p *TimeCode) Frame() int {
+	fps := p.Type.FramesPerSecond()
+	frame := ((int(p.Hours)*60+int(p.Minutes))*60+int(p.Seconds))*fps + int(p.Frames)
+
+	if p.Type == TimeCodeDF {
+		minutes := int(p.Hours)*60 + int(p.Minutes)
+		frame -= 2 * (minutes - minutes/10)
+	}
+
+	return frame
+}
+
+// Duration returns the position given by the timecode.
+func (p *TimeCode) Duration() time.Duration {
+	return time.Duration(p.Frame()) * p.Type.frameDuration()
+}
+
+// SetDuration sets the timecode to a position, wrapping after 24 hours.
+func (p *TimeCode) SetDuration(d time.Duration) {
+	if d < 0 {
+		d = 0
+	}
+
+	frame := int(d / p.Type.frameDuration())
+	if p.Type == TimeCodeDF {
+		tens, rest := frame/dfFramesPer10Minutes, frame%dfFramesPer10Minutes
+		frame += 18 * tens
+		if rest > 1 {
+			frame += 2 * ((rest - 2) / dfFramesPerMinute)
+		}
+	}
+
+	fps := p.Type.FramesPerSecond()
+	p.Frames = uint8(frame % fps)
+	p.Seconds = uint8(frame / fps % 60)
+	p.Minutes = uint8(frame / fps / 60 % 60)
+	p.Hours = uint8(frame / fps / 3600 % 24)
+}
+
+func (p *TimeCode) String() string {
+	sep := ":"
+	if p.Type == TimeCodeDF {
+		sep = ";"
+	}
+	return fmt.Sprintf("%02d:%02d:%02d%s%02d", p.Hours, p.Minutes, p.Seconds, sep, p.Frames)
+}
+
+func (p *TimeCode) Read(r wire.Reader) error {
+	return wire.Decode(r, p)
+}
+
+func (p *TimeCode) Write(w io.Writer) error {
+	return wire.Encode(w, p)
+}
+
+// TimeCodeTimeout is how long a TimeCodeClock keeps running after the last
+// TimeCode it received. After that it holds its position until timecode
+// resumes.
+const TimeCodeTimeout = time.Second
+
+// TimeCodeClock is a show clock chasing received TimeCode. Between frames it
+// runs freely from the last received position.
+type TimeCodeClock struct {
+	sub      *Subscription
+	streamID uint8
+
+	mu       sync.Mutex
+	position time.Duration
+	received time.Time
+}
+
+// NewTimeCodeClock creates a clock chasing the TimeCode stream with the
+// given ID, until it is closed.
+func NewTimeCodeClock(t Transport, streamID uint8) *TimeCodeClock {
+	c := &TimeCodeClock{
+		sub:      t.Subscribe(OpTimeCode),
+		streamID: streamID,
+	}
+
+	go c.chase()
+	return c
+}
+
+// Now returns the current position of the clock.
+func (c *TimeCodeClock) Now() time.Duration {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.received.IsZero() {
+		return 0
+	}
+
+	elapsed := time.Since(c.received)
+	if elapsed > TimeCodeTimeout {
+		elapsed = TimeCodeTimeout
+	}
+	return c.position + elapsed
+}
+
+// Running reports whether the clock has received TimeCode recently.
+func (c *TimeCodeClock) Running() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return !c.received.IsZero() && time.Since(c.received) <= TimeCodeTimeout
+}
+
+// Close stops the clock following TimeCode.
+func (c *TimeCodeClock) Close() {
+	c.sub.Close()
+}
+
+func (c *TimeCodeClock) chase() {
+	for msg := range c.sub.C {
+		p := msg.Packet.(*TimeCode)
+		if p.StreamID != c.streamID {
+			continue
+		}
+
+		c.mu.Lock()
+		c.position = p.Duration()
+		c.received = time.Now()
+		c.mu.Unlock()
+	}
+}
@@ -0,0 +1,86 @@
+package artnet
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTimeCodeFrame(t *testing.T) {
+	tests := []struct {
+		typ   TimeCodeType
+		tc    string
+		h     uint8
+		m     uint8
+		s     uint8
+		f     uint8
+		frame int
+	}{
+		{TimeCodeFilm, "00:00:01:00", 0, 0, 1, 0, 24},
+		{TimeCodeFilm, "00:00:00:23", 0, 0, 0, 23, 23},
+		{TimeCodeEBU, "01:00:00:00", 1, 0, 0, 0, 90000},
+		{TimeCodeSMPTE, "00:01:00:00", 0, 1, 0, 0, 1800},
+		{TimeCodeSMPTE, "23:59:59:29", 23, 59, 59, 29, 2591999},
+		{TimeCodeDF, "00:00:59;29", 0, 0, 59, 29, 1799},
+		{TimeCodeDF, "00:01:00;02", 0, 1, 0, 2, 1800},
+		{TimeCodeDF, "00:02:00;02", 0, 2, 0, 2, 3598},
+		{TimeCodeDF, "00:09:59;29", 0, 9, 59, 29, 17981},
+		{TimeCodeDF, "00:10:00;00", 0, 10, 0, 0, 17982},
+		{TimeCodeDF, "00:10:00;01", 0, 10, 0, 1, 17983},
+		{TimeCodeDF, "00:11:00;02", 0, 11, 0, 2, 19782},
+		{TimeCodeDF, "01:00:00;00", 1, 0, 0, 0, 107892},
+		{TimeCodeDF, "23:59:59;29", 23, 59, 59, 29, 2589407},
+	}
+
+	for _, tt := range tests {
+		p := &TimeCode{Hours: tt.h, Minutes: tt.m, Seconds: tt.s, Frames: tt.f, Type: tt.typ}
+		if got := p.Frame(); got != tt.frame {
+			t.Errorf("%s %s: frame %d, want %d", tt.typ, tt.tc, got, tt.frame)
+		}
+
+		d := time.Duration(tt.frame) * tt.typ.frameDuration()
+		if got := p.Duration(); got != d {
+			t.Errorf("%s %s: duration %v, want %v", tt.typ, tt.tc, got, d)
+		}
+		if got := NewTimeCode(d, tt.typ).String(); got != tt.tc {
+			t.Errorf("%s at %v: timecode %s, want %s", tt.typ, d, got, tt.tc)
+		}
+	}
+}
+
+func TestTimeCodeSetDuration(t *testing.T) {
+	df := TimeCodeDF.frameDuration()
+	tests := []struct {
+		typ  TimeCodeType
+		d    time.Duration
+		want string
+	}{
+		{TimeCodeSMPTE, -time.Second, "00:00:00:00"},
+		{TimeCodeEBU, 1500 * time.Millisecond, "00:00:01:12"},
+		{TimeCodeSMPTE, 24 * time.Hour, "00:00:00:00"},
+		{TimeCodeFilm, 25*time.Hour + 2*time.Second, "01:00:02:00"},
+		{TimeCodeDF, 2589408 * df, "00:00:00;00"},
+		{TimeCodeDF, 2589409 * df, "00:00:00;01"},
+		{TimeCodeDF, 1800*df - 1, "00:00:59;29"},
+	}
+
+	for _, tt := range tests {
+		if got := NewTimeCode(tt.d, tt.typ).String(); got != tt.want {
+			t.Errorf("%s at %v: timecode %s, want %s", tt.typ, tt.d, got, tt.want)
+		}
+	}
+}
+
+func TestTimeCodeDropFrames(t *testing.T) {
+	df := TimeCodeDF.frameDuration()
+	p := &TimeCode{Type: TimeCodeDF}
+
+	for frame := 0; frame < 2*dfFramesPer10Minutes; frame++ {
+		p.SetDuration(time.Duration(frame) * df)
+		if p.Seconds == 0 && p.Frames < 2 && p.Minutes%10 != 0 {
+			t.Fatalf("frame %d is %s, which drop-frame skips", frame, p)
+		}
+		if got := p.Frame(); got != frame {
+			t.Fatalf("frame %d is %s, which is frame %d", frame, p, got)
+		}
+	}
+}
@@ -0,0 +1,225 @@
+package artnet
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"lyra.codes/blinken/artnet/wire"
+	"lyra.codes/blinken/rdm"
+)
+
+var (
+	todRequestHeader = Header{Operation: OpDeviceTableRequest}
+	todDataHeader    = Header{Operation: OpDeviceTableData}
+	todControlHeader = Header{Operation: OpDeviceTableControl}
+)
+
+// RDMVersion is the RDM standard version carried in TodData and RDM messages.
+const RDMVersion uint8 = 0x01
+
+// maxTodAddresses is the number of port-addresses a TodRequest can ask for.
+const maxTodAddresses = 32
+
+// TodCommand is the command in a TodRequest.
+type TodCommand uint8
+
+const (
+	TodFull TodCommand = 0x00
+)
+
+// TodRequest is the contents of an OpTodRequest message, which asks nodes
+// for the table of RDM devices behind their outputs.
+type TodRequest struct {
+	Header
+	Version   Version
+	_         [9]byte `wire:"skip"`
+	Net       uint8
+	Command   TodCommand
+	Count     uint8
+	Addresses []uint8 `wire:"size=32,count=Count"`
+}
+
+// NewTodRequest creates a TodRequest for one or more port-addresses, which
+// must all be in the same net.
+func NewTodRequest(addresses ...Address) (*TodRequest, error) {
+	if len(addresses) == 0 || len(addresses) > maxTodAddresses {
+		return nil, fmt.Errorf("a TodRequest must ask for 1 to %d addresses", maxTodAddresses)
+	}
+
+	p := &TodRequest{
+		Header:  todRequestHeader,
+		Version: Version14,
+		Net:     addresses[0].Net(),
+		Command: TodFull,
+	}
+
+	for _, addr := range addresses {
+		if addr.Net() != p.Net {
+			return nil, fmt.Errorf("address %s is not in net %d", addr, p.Net)
+		}
+		p.Addresses = append(p.Addresses, uint8(addr))
+	}
+
+	return p, nil
+}
+
+func (p *TodRequest) Read(r wire.Reader) error {
+	return wire.Decode(r, p)
+}
+
+func (p *TodRequest) Write(w io.Writer) error {
+	return wire.Encode(w, p)
+}
+
+// TodResponse is the status of a TodData message.
+type TodResponse uint8
+
+const (
+	TodResponseFull TodResponse = 0x00
+	TodResponseNak  TodResponse = 0xFF
+)
+
+// TodData is the contents of an OpTodData message, which lists the RDM
+// devices a node has discovered on one port.
+type TodData struct {
+	Header
+	Version    Version
+	RDMVersion uint8
+	Port       uint8
+	_          [6]byte `wire:"skip"`
+	BindIndex  uint8
+	Net        uint8
+	Response   TodResponse
+	SubUni     uint8
+	Total      uint16
+	Block      uint8
+	Count      uint8
+	UIDs       []rdm.UID `wire:"size=Count,max=200"`
+}
+
+// NewTodData creates a TodData listing devices found on a port-address.
+func NewTodData(port uint8, addr Address, total uint16, block uint8, uids []rdm.UID) *TodData {
+	return &TodData{
+		Header:     todDataHeader,
+		Version:    Version14,
+		RDMVersion: RDMVersion,
+		Port:       port,
+		Net:        addr.Net(),
+		SubUni:     uint8(addr),
+		Response:   TodResponseFull,
+		Total:      total,
+		Block:      block,
+		UIDs:       uids,
+	}
+}
+
+// PortAddress returns the port-address whose devices are listed.
+func (p *TodData) PortAddress() Address {
+	return Address(p.Net)<<8 | Address(p.SubUni)
+}
+
+func (p *TodData) Read(r wire.Reader) error {
+	return wire.Decode(r, p)
+}
+
+func (p *TodData) Write(w io.Writer) error {
+	return wire.Encode(w, p)
+}
+
+// TodControlCommand is the command in a TodControl.
+type TodControlCommand uint8
+
+const (
+	TodControlNone   TodControlCommand = 0x00
+	TodControlFlush  TodControlCommand = 0x01
+	TodControlEnd    TodControlCommand = 0x02
+	TodControlIncOn  TodControlCommand = 0x03
+	TodControlIncOff TodControlCommand = 0x04
+)
+
+// TodControl is the contents of an OpTodControl message, which controls RDM
+// discovery on a node's port.
+type TodControl struct {
+	Header
+	Version Version
+	_       [9]byte `wire:"skip"`
+	Net     uint8
+	Command TodControlCommand
+	SubUni  uint8
+}
+
+// NewTodControl creates a TodControl for a port-address.
+func NewTodControl(addr Address, command TodControlCommand) *TodControl {
+	return &TodControl{
+		Header:  todControlHeader,
+		Version: Version14,
+		Net:     addr.Net(),
+		Command: command,
+		SubUni:  uint8(addr),
+	}
+}
+
+// PortAddress returns the port-address the command is for.
+func (p *TodControl) PortAddress() Address {
+	return Address(p.Net)<<8 | Address(p.SubUni)
+}
+
+func (p *TodControl) Read(r wire.Reader) error {
+	return wire.Decode(r, p)
+}
+
+func (p *TodControl) Write(w io.Writer) error {
+	return wire.Encode(w, p)
+}
+
+// TableOfDevices asks a node for the RDM devices on a port-address, and waits
+// until it has replied with all of them.
+func TableOfDevices(ctx context.Context, t Transport, node *Node, addr Address) ([]rdm.UID, error) {
+	req, err := NewTodRequest(addr)
+	if err != nil {
+		return nil, err
+	}
+
+	sub := t.Subscribe(OpDeviceTableData)
+	defer sub.Close()
+
+	if err := t.Send(node.NetworkAddress, req); err != nil {
+		return nil, err
+	}
+
+	blocks := make(map[uint8][]rdm.UID)
+	found := 0
+
+	for {
+		select {
+		case msg, ok := <-sub.C:
+			if !ok {
+				return nil, fmt.Errorf("transport closed")
+			}
+
+			p := msg.Packet.(*TodData)
+			if p.PortAddress() != addr || !msg.From.IP.Equal(node.NetworkAddress.IP) {
+				continue
+			}
+			if p.Response == TodResponseNak {
+				return nil, fmt.Errorf("node %s refused the TodRequest for %s", node.ShortName, addr)
+			}
+
+			if _, ok := blocks[p.Block]; !ok {
+				found += len(p.UIDs)
+			}
+			blocks[p.Block] = p.UIDs
+
+			if found >= int(p.Total) {
+				uids := make([]rdm.UID, 0, found)
+				for i := 0; i < len(blocks); i++ {
+					uids = append(uids, blocks[uint8(i)]...)
+				}
+				return uids, nil
+			}
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
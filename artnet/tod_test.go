@@ -0,0 +1,96 @@
+package artnet
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+
+	"lyra.codes/blinken/artnet/wire"
+	"lyra.codes/blinken/rdm"
+)
+
+func readBack(t *testing.T, p Packet) Packet {
+	t.Helper()
+
+	b, err := encode(p)
+	if err != nil {
+		t.Fatalf("%T.Write: %v", p, err)
+	}
+	got, err := ReadPacket(b, wire.Strict)
+	if err != nil {
+		t.Fatalf("ReadPacket %T: %v", p, err)
+	}
+	return got
+}
+
+func TestTodRequest(t *testing.T) {
+	p, err := NewTodRequest(0x0312, 0x0334)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := readBack(t, p).(*TodRequest)
+	if got.Net != 3 || got.Command != TodFull || got.Count != 2 {
+		t.Errorf("TodRequest is net %d, command %d, count %d", got.Net, got.Command, got.Count)
+	}
+	if want := []uint8{0x12, 0x34}; !bytes.Equal(got.Addresses, want) {
+		t.Errorf("TodRequest addresses are %x, want %x", got.Addresses, want)
+	}
+
+	if _, err := NewTodRequest(0x0112, 0x0212); err == nil {
+		t.Error("NewTodRequest accepted addresses in different nets")
+	}
+	if _, err := NewTodRequest(); err == nil {
+		t.Error("NewTodRequest accepted no addresses")
+	}
+}
+
+func TestTodData(t *testing.T) {
+	uids := []rdm.UID{rdm.NewUID(0x7a70, 1), rdm.NewUID(0x7a70, 2), rdm.NewUID(0x4144, 0xdeadbeef)}
+	p := NewTodData(2, 0x0512, 3, 0, uids)
+
+	got := readBack(t, p).(*TodData)
+	if got.PortAddress() != 0x0512 || got.Port != 2 || got.RDMVersion != RDMVersion {
+		t.Errorf("TodData is for port %d at %s, RDM version %d", got.Port, got.PortAddress(), got.RDMVersion)
+	}
+	if got.Total != 3 || got.Block != 0 || got.Count != 3 || got.Response != TodResponseFull {
+		t.Errorf("TodData lists %d of %d in block %d, response %d", got.Count, got.Total, got.Block, got.Response)
+	}
+	if !reflect.DeepEqual(got.UIDs, uids) {
+		t.Errorf("TodData UIDs are %v, want %v", got.UIDs, uids)
+	}
+}
+
+func TestTodControl(t *testing.T) {
+	got := readBack(t, NewTodControl(0x0201, TodControlFlush)).(*TodControl)
+	if got.PortAddress() != 0x0201 || got.Command != TodControlFlush {
+		t.Errorf("TodControl is %d for %s", got.Command, got.PortAddress())
+	}
+}
+
+func TestRDM(t *testing.T) {
+	m, err := rdm.SetDMXStartAddress(rdm.NewUID(0x4144, 9), rdm.NewUID(0x7a70, 1), 6, 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p, err := NewRDM(0x0107, m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.Data[0] != rdm.SubStartCode {
+		t.Errorf("RDM data starts with 0x%02x, want the sub-start code", p.Data[0])
+	}
+
+	got := readBack(t, p).(*RDM)
+	if got.PortAddress() != 0x0107 {
+		t.Errorf("RDM is for %s, want 1:0:7", got.PortAddress())
+	}
+
+	msg, err := got.Message()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(msg, m) {
+		t.Errorf("RDM message is %+v, want %+v", msg, m)
+	}
+}
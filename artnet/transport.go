@@ -3,18 +3,87 @@ package artnet
 import (
 	"bytes"
 	"context"
-	"encoding/hex"
 	"fmt"
 	"net"
 	"sync"
+
+	"lyra.codes/blinken/artnet/wire"
 )
 
 type Transport interface {
 	Send(to *net.UDPAddr, packet Packet) error
+
+	// Nodes delivers the node described by each PollReply received. Only
+	// the latest node is kept for a reader which isn't keeping up, so
+	// receiving never waits for it.
 	Nodes() <-chan *Node
+
+	// Subscribe delivers every received packet with one of the given
+	// operations until the Subscription is closed.
+	Subscribe(ops ...Operation) *Subscription
+
+	// Diagnostics delivers DiagData messages at or above the given priority
+	// until the Subscription is closed.
+	Diagnostics(priority DiagnosticPriority) *Subscription
+
+	// Triggers delivers Trigger messages meant for devices with the given
+	// OEM code, including standard triggers, until the Subscription is closed.
+	Triggers(oem uint16) *Subscription
+
+	// Universes delivers DMX messages for the given port-addresses, or for
+	// every port-address if none are given, until the Subscription is
+	// closed. Messages which arrive out of order are dropped. The DMX data
+	// in each message is shared between subscribers and must not be modified.
+	Universes(addresses ...Address) *Subscription
+}
+
+// Message is a packet received by a Transport.
+type Message struct {
+	From   *net.UDPAddr
+	Packet Packet
+}
+
+// Subscription is a stream of received packets.
+type Subscription struct {
+	C <-chan Message
+
+	c      chan Message
+	ops    []Operation
+	filter func(Message) bool
+	cancel func(s *Subscription)
+}
+
+// subscriptionBuffer is the number of messages a Subscription can hold
+// before the transport starts dropping messages for it.
+const subscriptionBuffer = 64
+
+// Close stops delivery of messages to the subscription.
+func (s *Subscription) Close() {
+	s.cancel(s)
+}
+
+func (s *Subscription) wants(op Operation, msg Message) bool {
+	for _, o := range s.ops {
+		if o == op {
+			return s.filter == nil || s.filter(msg)
+		}
+	}
+	return false
 }
 
-func Listen(ctx context.Context, addr *net.UDPAddr) (Transport, error) {
+// ListenOption configures a Transport created by Listen.
+type ListenOption func(t *networkTransport)
+
+// ListenMode sets how strictly received packets are parsed. By default a
+// Transport parses leniently, to accept the older and newer revisions of
+// packets sent by nodes on the network.
+func ListenMode(mode wire.Mode) ListenOption {
+	return func(t *networkTransport) {
+		t.mode = mode
+	}
+}
+
+func Listen(ctx context.Context, addr *net.UDPAddr, options ...ListenOption) (Transport, error) {
 	if addr == nil {
 		addr = &net.UDPAddr{Port: Port}
 	}
@@ -33,8 +102,20 @@ func Listen(ctx context.Context, addr *net.UDPAddr) (Transport, error) {
 				return make([]byte, 2048)
 			},
 		},
+		sendPool: &sync.Pool{
+			New: func() interface{} {
+				buf := make([]byte, 0, 1024)
+				return &buf
+			},
+		},
 		recv:  make(chan networkMessage),
 		nodes: make(chan *Node, 1),
+		subs:  make(map[*Subscription]struct{}),
+		mode:  wire.Lenient,
+	}
+
+	for _, opt := range options {
+		opt(t)
 	}
 
 	go t.receive()
@@ -47,31 +128,156 @@ type networkTransport struct {
 	ctx  context.Context
 	conn *net.UDPConn
 
-	pool  *sync.Pool
-	recv  chan networkMessage
-	nodes chan *Node
+	pool     *sync.Pool
+	sendPool *sync.Pool
+	recv     chan networkMessage
+	nodes    chan *Node
+	mode     wire.Mode
+
+	mu     sync.Mutex
+	subs   map[*Subscription]struct{}
+	closed bool
 }
 
 func (t *networkTransport) Send(to *net.UDPAddr, packet Packet) error {
-	buf := bytes.Buffer{}
-	if err := packet.Write(&buf); err != nil {
+	a, ok := packet.(Appender)
+	if !ok {
+		buf := bytes.Buffer{}
+		if err := packet.Write(&buf); err != nil {
+			return err
+		}
+
+		_, err := t.conn.WriteToUDP(buf.Bytes(), to)
 		return err
 	}
 
-	if _, err := t.conn.WriteToUDP(buf.Bytes(), to); err != nil {
+	buf := t.sendPool.Get().(*[]byte)
+	defer t.sendPool.Put(buf)
+
+	b, err := a.AppendBinary((*buf)[:0])
+	*buf = b
+	if err != nil {
 		return err
 	}
 
-	return nil
+	_, err = t.conn.WriteToUDP(b, to)
+	return err
 }
 
 func (t *networkTransport) Nodes() <-chan *Node {
 	return t.nodes
 }
 
+func (t *networkTransport) Subscribe(ops ...Operation) *Subscription {
+	return t.subscribe(nil, ops...)
+}
+
+func (t *networkTransport) Diagnostics(priority DiagnosticPriority) *Subscription {
+	return t.subscribe(func(msg Message) bool {
+		return msg.Packet.(*DiagData).Priority >= priority
+	}, OpDiagData)
+}
+
+func (t *networkTransport) Triggers(oem uint16) *Subscription {
+	return t.subscribe(func(msg Message) bool {
+		return msg.Packet.(*Trigger).For(oem)
+	}, OpTrigger)
+}
+
+func (t *networkTransport) Universes(addresses ...Address) *Subscription {
+	type source struct {
+		ip      string
+		port    int
+		address Address
+	}
+	last := make(map[source]uint8)
+
+	return t.subscribe(func(msg Message) bool {
+		p := msg.Packet.(*DMX)
+		if len(addresses) > 0 && !containsAddress(addresses, p.Address) {
+			return false
+		}
+
+		key := source{string(msg.From.IP), msg.From.Port, p.Address}
+		if seq, ok := last[key]; ok && !InSequence(seq, p.Sequence) {
+			return false
+		}
+		last[key] = p.Sequence
+
+		return true
+	}, OpDMX)
+}
+
+func containsAddress(addresses []Address, addr Address) bool {
+	for _, a := range addresses {
+		if a == addr {
+			return true
+		}
+	}
+	return false
+}
+
+// subscribe creates a Subscription to the given operations which only
+// receives messages accepted by filter, if it isn't nil.
+func (t *networkTransport) subscribe(filter func(Message) bool, ops ...Operation) *Subscription {
+	c := make(chan Message, subscriptionBuffer)
+	s := &Subscription{C: c, c: c, ops: ops, filter: filter, cancel: t.unsubscribe}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.closed {
+		close(c)
+	} else {
+		t.subs[s] = struct{}{}
+	}
+
+	return s
+}
+
+func (t *networkTransport) unsubscribe(s *Subscription) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if _, ok := t.subs[s]; ok {
+		delete(t.subs, s)
+		close(s.c)
+	}
+}
+
+func (t *networkTransport) publish(op Operation, msg Message) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for s := range t.subs {
+		if !s.wants(op, msg) {
+			continue
+		}
+
+		select {
+		case s.c <- msg:
+		default:
+			// The subscriber isn't keeping up; drop the message rather
+			// than stalling every other stream.
+		}
+	}
+}
+
+func (t *networkTransport) closeSubscriptions() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for s := range t.subs {
+		close(s.c)
+	}
+	t.subs = nil
+	t.closed = true
+}
+
 type networkMessage struct {
-	addr *net.UDPAddr
-	body []byte
+	addr    *net.UDPAddr
+	body    []byte
+	release func()
 }
 
 func (t *networkTransport) buffer() ([]byte, func()) {
@@ -84,6 +290,7 @@ func (t *networkTransport) buffer() ([]byte, func()) {
 }
 
 func (t *networkTransport) process() {
+	defer close(t.nodes)
 	done := t.ctx.Done()
 
 	for {
@@ -95,6 +302,7 @@ func (t *networkTransport) process() {
 			}
 
 			t.handle(msg.addr, msg.body)
+			msg.release()
 		case <-done:
 			fmt.Println("Shutting down")
 			t.conn.Close() // TODO(lyra): blackout on shutdown
@@ -105,33 +313,92 @@ func (t *networkTransport) process() {
 
 func (t *networkTransport) handle(from *net.UDPAddr, body []byte) {
 	head := Header{}
-	hbuf := bytes.NewBuffer(body[:HeaderLength])
-	if err := head.Read(hbuf); err != nil {
+	if err := head.unmarshalBinary(body); err != nil {
 		fmt.Printf("invalid packet: %v\n", err)
 		return
 	}
 
-	fmt.Printf("Got 0x%04x message from %s\n", head.Operation, from)
-	fmt.Println(hex.Dump(body))
+	p := newPacket(head)
+	if p == nil {
+		fmt.Printf("unknown operation 0x%04x from %s\n", head.Operation, from)
+		return
+	}
+
+	if err := readPacket(p, body, t.mode); err != nil {
+		fmt.Printf("Error reading 0x%04x from %s: %v\n", head.Operation, from, err)
+		return
+	}
+
+	if reply, ok := p.(*PollReply); ok {
+		t.sendNode(reply.ToNode())
+	}
+
+	t.publish(head.Operation, Message{From: from, Packet: p})
+}
 
-	buf := bytes.NewBuffer(body)
+// sendNode delivers a node without waiting for a reader, replacing the node
+// waiting to be read if there is one. Only process sends nodes, so the
+// channel has room once the waiting node is taken.
+func (t *networkTransport) sendNode(node *Node) {
+	select {
+	case t.nodes <- node:
+		return
+	default:
+	}
+
+	select {
+	case <-t.nodes:
+	default:
+	}
+	t.nodes <- node
+}
+
+// newPacket returns an empty packet for the operation in the given header,
+// or nil if the operation isn't supported.
+func newPacket(head Header) Packet {
 	switch head.Operation {
+	case OpPoll:
+		return &Poll{Header: head}
 	case OpPollReply:
-		p := &PollReply{Header: head}
-		if err := p.Read(buf); err != nil {
-			fmt.Printf("Error reading OpPollReply: %v\n", err)
-			return
-		}
-
-		t.nodes <- p.ToNode()
+		return &PollReply{Header: head}
+	case OpDMX:
+		return &DMX{Header: head}
+	case OpSync:
+		return &Sync{Header: head}
+	case OpDiagData:
+		return &DiagData{Header: head}
+	case OpCommand:
+		return &Command{Header: head}
+	case OpInput:
+		return &Input{Header: head}
+	case OpDeviceTableRequest:
+		return &TodRequest{Header: head}
+	case OpDeviceTableData:
+		return &TodData{Header: head}
+	case OpDeviceTableControl:
+		return &TodControl{Header: head}
+	case OpRDM:
+		return &RDM{Header: head}
+	case OpTimeCode:
+		return &TimeCode{Header: head}
+	case OpTrigger:
+		return &Trigger{Header: head}
+	case OpFirmwareMaster:
+		return &FirmwareMaster{Header: head}
+	case OpFirmwareReply:
+		return &FirmwareReply{Header: head}
+	case OpIpProg:
+		return &IpProg{Header: head}
+	case OpIpProgReply:
+		return &IpProgReply{Header: head}
 	default:
-		fmt.Println("unknown operation")
+		return nil
 	}
 }
 
 func (t *networkTransport) receive() {
 	defer close(t.recv)
-	defer close(t.nodes)
+	defer t.closeSubscriptions()
 
 	for {
 		err := t.receiveNext()
@@ -147,11 +414,13 @@ func (t *networkTransport) receive() {
 
 func (t *networkTransport) receiveNext() error {
 	buf, release := t.buffer()
-	defer release()
 
+	// The buffer is released by process once the message is handled.
 	n, from, err := t.conn.ReadFromUDP(buf)
 	if n > 0 {
-		t.recv <- networkMessage{from, buf[:n]}
+		t.recv <- networkMessage{from, buf[:n], release}
+	} else {
+		release()
 	}
 
 	return err
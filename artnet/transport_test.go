@@ -0,0 +1,36 @@
+package artnet
+
+import (
+	"net"
+	"testing"
+
+	"lyra.codes/blinken/artnet/wire"
+)
+
+func TestHandleNodes(t *testing.T) {
+	tr := &networkTransport{
+		nodes: make(chan *Node, 1),
+		subs:  make(map[*Subscription]struct{}),
+		mode:  wire.Lenient,
+	}
+	sub := tr.Subscribe(OpPollReply)
+	from := &net.UDPAddr{IP: net.IPv4(2, 0, 0, 10), Port: Port}
+
+	// Nobody reads the nodes, which mustn't stop replies being handled.
+	for _, name := range []string{"first", "second", "third"} {
+		p := testPollReply()
+		p.ShortName = name
+		b, err := p.AppendBinary(nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		tr.handle(from, b)
+	}
+
+	if node := <-tr.Nodes(); node.ShortName != "third" {
+		t.Errorf("node is %s, want the latest", node.ShortName)
+	}
+	if n := len(sub.C); n != 3 {
+		t.Errorf("%d replies published, want 3", n)
+	}
+}
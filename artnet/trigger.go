@@ -0,0 +1,110 @@
+package artnet
+
+import (
+	"fmt"
+	"io"
+
+	"lyra.codes/blinken/artnet/wire"
+)
+
+var (
+	triggerHeader = Header{Operation: OpTrigger}
+)
+
+// OEMAll is the OEM code of triggers with the standard Key meanings, which
+// every device should act on.
+const OEMAll uint16 = 0xFFFF
+
+// triggerDataLength is the length of the payload of a Trigger.
+const triggerDataLength = 512
+
+// TriggerKey is the kind of a standard trigger.
+type TriggerKey uint8
+
+const (
+	// KeyASCII triggers the ASCII key given in the SubKey, as if it had
+	// been pressed on a keyboard.
+	KeyASCII TriggerKey = 0
+	// KeyMacro runs the macro numbered by the SubKey.
+	KeyMacro TriggerKey = 1
+	// KeySoft triggers the soft key numbered by the SubKey.
+	KeySoft TriggerKey = 2
+	// KeyShow runs the show numbered by the SubKey.
+	KeyShow TriggerKey = 3
+)
+
+func (k TriggerKey) String() string {
+	switch k {
+	case KeyASCII:
+		return "KeyAscii"
+	case KeyMacro:
+		return "KeyMacro"
+	case KeySoft:
+		return "KeySoft"
+	case KeyShow:
+		return "KeyShow"
+	default:
+		return fmt.Sprintf("TriggerKey(%d)", uint8(k))
+	}
+}
+
+// Trigger is the contents of an OpTrigger message, a remote show-control
+// event. When OEM is OEMAll, Key is one of the standard TriggerKeys;
+// otherwise the meaning of Key, SubKey and Data is defined by the OEM.
+type Trigger struct {
+	Header
+	Version Version
+	_       [2]byte `wire:"skip"`
+	OEM     uint16
+	Key     TriggerKey
+	SubKey  uint8
+	Data    []byte `wire:"size=512"`
+}
+
+// NewTrigger creates a standard Trigger operation.
+func NewTrigger(key TriggerKey, subKey uint8) *Trigger {
+	return NewOEMTrigger(OEMAll, key, subKey, nil)
+}
+
+// NewOEMTrigger creates a Trigger operation for an OEM's devices, with up to
+// 512 bytes of payload.
+func NewOEMTrigger(oem uint16, key TriggerKey, subKey uint8, data []byte) *Trigger {
+	return &Trigger{
+		Header:  triggerHeader,
+		Version: Version14,
+		OEM:     oem,
+		Key:     key,
+		SubKey:  subKey,
+		Data:    data,
+	}
+}
+
+// Standard reports whether the trigger uses the standard Key meanings.
+func (p *Trigger) Standard() bool {
+	return p.OEM == OEMAll
+}
+
+// For reports whether a device with the given OEM code should act on the trigger.
+func (p *Trigger) For(oem uint16) bool {
+	return p.OEM == OEMAll || p.OEM == oem
+}
+
+func (p *Trigger) String() string {
+	if p.Standard() {
+		return fmt.Sprintf("%s %d", p.Key, p.SubKey)
+	}
+	return fmt.Sprintf("OEM 0x%04x key %d/%d", p.OEM, uint8(p.Key), p.SubKey)
+}
+
+func (p *Trigger) Read(r wire.Reader) error {
+	return wire.Decode(r, p)
+}
+
+func (p *Trigger) Write(w io.Writer) error {
+	return wire.Encode(w, p)
+}
+
+// SendTrigger broadcasts a Trigger to every device on the network.
+func SendTrigger(t Transport, p *Trigger) error {
+	return t.Send(Broadcast, p)
+}
@@ -70,7 +70,7 @@ func (b *Builder) String(name, v string, capacity int) *Builder {
 
 func (b *Builder) IPv4(name string, v net.IP) *Builder {
 	ip := v.To4()
-	if ip != nil {
+	if ip == nil {
 		b.error(name, fmt.Errorf("IP %q is not an IPv4 address", v))
 		return b
 	}
@@ -89,3 +89,11 @@ func (b *Builder) MAC(name string, v net.HardwareAddr) *Builder {
 
 	return b
 }
+
+func (b *Builder) Bytes(name string, v []byte) *Builder {
+	if _, err := b.Writer.Write(v); err != nil {
+		b.error(name, err)
+	}
+
+	return b
+}
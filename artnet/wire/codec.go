@@ -0,0 +1,450 @@
+package wire
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Encoder is implemented by types which write their own wire encoding.
+type Encoder interface {
+	EncodeWire(b *Builder)
+}
+
+// Decoder is implemented by types which read their own wire encoding.
+type Decoder interface {
+	DecodeWire(p *Parser)
+}
+
+// Encode writes the wire encoding of the struct pointed to by v.
+//
+// Fields are encoded in order, as described by their `wire` tags:
+//
+//	wire:"-"            the field is not encoded
+//	wire:"le"           integers are little-endian (the default is big-endian)
+//	wire:"skip"         the field is padding: zeros are written, and it is
+//	                    skipped when decoding; use with blank array fields
+//	wire:"size=N"       strings are NUL-terminated in N bytes, and slices
+//	                    have N elements
+//	wire:"size=Field"   the size is given by an earlier integer field, which
+//	                    Encode writes from the length of this field
+//	wire:"count=Field"  an earlier integer field holds the number of elements
+//	                    used in a slice of fixed size, which Encode writes
+//	                    from the length of this field; the rest are padding
+//	wire:"max=N"        limits the size given by a field
+//	wire:"rest"         a byte slice holding the rest of the message
+//
+// Encode doesn't change v: the fields holding sizes are encoded with the
+// lengths of the fields they describe, whatever their values. It fails if a
+// length is too large for its size field.
+func Encode(w io.Writer, v interface{}) error {
+	rv, err := structPointer(v)
+	if err != nil {
+		return err
+	}
+
+	b := Build(w)
+	encodeStruct(b, rv)
+	return b.Err()
+}
+
+// Decode strictly reads the wire encoding of the struct pointed to by v. See
+// Encode for the meaning of field tags. The size field of a string is set to
+// the length of the string read plus its NUL, as Encode writes it, even if
+// the message gave it more room.
+func Decode(r Reader, v interface{}) error {
+	return DecodeMode(r, v, Strict)
+}
+
+// DecodeMode reads the wire encoding of the struct pointed to by v, in the
+// given mode.
+func DecodeMode(r Reader, v interface{}, mode Mode) error {
+	rv, err := structPointer(v)
+	if err != nil {
+		return err
+	}
+
+	p := ParseMode(r, mode)
+	decodeStruct(p, rv)
+	return p.End(rv.Type().Name())
+}
+
+func structPointer(v interface{}) (reflect.Value, error) {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.Elem().Kind() != reflect.Struct {
+		return reflect.Value{}, fmt.Errorf("wire: expected a pointer to a struct, got %T", v)
+	}
+	return rv.Elem(), nil
+}
+
+// tag is the parsed `wire` tag of a struct field.
+type tag struct {
+	omit   bool
+	order  binary.ByteOrder
+	skip   bool
+	rest   bool
+	size   int
+	sizeOf string
+	count  string
+	max    int
+}
+
+func parseTag(f reflect.StructField) tag {
+	t := tag{order: binary.BigEndian, size: -1, max: -1}
+
+	s, ok := f.Tag.Lookup("wire")
+	if !ok {
+		return t
+	}
+	if s == "-" {
+		t.omit = true
+		return t
+	}
+
+	for _, opt := range strings.Split(s, ",") {
+		key, value := opt, ""
+		if eq := strings.IndexByte(opt, '='); eq >= 0 {
+			key, value = opt[:eq], opt[eq+1:]
+		}
+
+		switch key {
+		case "le":
+			t.order = binary.LittleEndian
+		case "be":
+			t.order = binary.BigEndian
+		case "skip":
+			t.skip = true
+		case "rest":
+			t.rest = true
+		case "size":
+			if n, err := strconv.Atoi(value); err == nil {
+				t.size = n
+			} else {
+				t.sizeOf = value
+			}
+		case "count":
+			t.count = value
+		case "max":
+			n, err := strconv.Atoi(value)
+			if err != nil {
+				panic(fmt.Sprintf("wire: invalid max %q on field %s", value, f.Name))
+			}
+			t.max = n
+		default:
+			panic(fmt.Sprintf("wire: unknown option %q on field %s", key, f.Name))
+		}
+	}
+
+	return t
+}
+
+var (
+	ipType      = reflect.TypeOf(net.IP{})
+	macType     = reflect.TypeOf(net.HardwareAddr{})
+	udpAddrType = reflect.TypeOf(net.UDPAddr{})
+	byteType    = reflect.TypeOf(byte(0))
+	encoderType = reflect.TypeOf((*Encoder)(nil)).Elem()
+	decoderType = reflect.TypeOf((*Decoder)(nil)).Elem()
+)
+
+func encodeStruct(b *Builder, v reflect.Value) {
+	t := v.Type()
+
+	// The sizes of later fields are worked out first, to be encoded in
+	// place of the fields which hold them.
+	var sizes map[string]uint64
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tg := parseTag(f)
+		name := tg.sizeOf
+		if name == "" {
+			name = tg.count
+		}
+		if name == "" {
+			continue
+		}
+
+		n := v.Field(i).Len()
+		if f.Type.Kind() == reflect.String && tg.sizeOf != "" {
+			n++
+		}
+
+		if _, ok := t.FieldByName(name); !ok {
+			panic(fmt.Sprintf("wire: size field %s of %s.%s not found", name, t.Name(), f.Name))
+		}
+		if sizes == nil {
+			sizes = make(map[string]uint64)
+		}
+		sizes[name] = uint64(n)
+	}
+
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tg := parseTag(f)
+		if tg.omit || b.err != nil {
+			continue
+		}
+
+		field := v.Field(i)
+		if n, ok := sizes[f.Name]; ok {
+			field = reflect.New(f.Type).Elem()
+			switch field.Kind() {
+			case reflect.Uint8, reflect.Uint16, reflect.Uint32:
+			default:
+				panic(fmt.Sprintf("wire: size field %s of %s is not an unsigned integer", f.Name, t.Name()))
+			}
+			if field.OverflowUint(n) {
+				b.error(f.Name, fmt.Errorf("size %d is too large for %d bits", n, field.Type().Bits()))
+				continue
+			}
+			field.SetUint(n)
+		}
+
+		encodeField(b, f.Name, v, field, tg)
+	}
+}
+
+func encodeField(b *Builder, name string, parent, v reflect.Value, tg tag) {
+	if tg.skip {
+		b.Bytes(name, make([]byte, v.Type().Size()))
+		return
+	}
+
+	if v.CanAddr() && v.Addr().Type().Implements(encoderType) {
+		v.Addr().Interface().(Encoder).EncodeWire(b)
+		return
+	}
+
+	switch v.Type() {
+	case ipType:
+		b.IPv4(name, v.Interface().(net.IP))
+		return
+	case macType:
+		mac := v.Interface().(net.HardwareAddr)
+		if len(mac) != 6 {
+			b.error(name, fmt.Errorf("MAC %q is not 6 bytes", mac))
+			return
+		}
+		b.MAC(name, mac)
+		return
+	case udpAddrType:
+		addr := v.Addr().Interface().(*net.UDPAddr)
+		b.IPv4(name+".IP", addr.IP).Int16(name+".Port", uint16(addr.Port), tg.order)
+		return
+	}
+
+	switch v.Kind() {
+	case reflect.Uint8:
+		b.Int8(name, uint8(v.Uint()))
+	case reflect.Uint16:
+		b.Int16(name, uint16(v.Uint()), tg.order)
+	case reflect.Uint32:
+		b.Int32(name, uint32(v.Uint()), tg.order)
+	case reflect.String:
+		size := fieldSize(tg, v.Len()+1)
+		if tg.max >= 0 && size > tg.max {
+			b.error(name, fmt.Errorf("%d bytes is more than %d", size, tg.max))
+			return
+		}
+		b.String(name, v.String(), size)
+	case reflect.Slice:
+		n := v.Len()
+		if !tg.rest {
+			n = fieldSize(tg, v.Len())
+		}
+		if tg.max >= 0 && n > tg.max {
+			b.error(name, fmt.Errorf("%d elements is more than %d", n, tg.max))
+			return
+		}
+		if v.Len() > n {
+			b.error(name, fmt.Errorf("%d elements is more than %d", v.Len(), n))
+			return
+		}
+
+		if v.Type().Elem() == byteType {
+			d := make([]byte, n)
+			reflect.Copy(reflect.ValueOf(d), v.Convert(reflect.TypeOf(d)))
+			b.Bytes(name, d)
+			return
+		}
+
+		zero := reflect.New(v.Type().Elem()).Elem()
+		for i := 0; i < n; i++ {
+			if i < v.Len() {
+				encodeField(b, name, parent, v.Index(i), tag{order: tg.order, size: -1, max: -1})
+			} else {
+				encodeField(b, name, parent, zero, tag{order: tg.order, size: -1, max: -1})
+			}
+		}
+	case reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			encodeField(b, name, parent, v.Index(i), tag{order: tg.order, size: -1, max: -1})
+		}
+	case reflect.Struct:
+		encodeStruct(b, v)
+	default:
+		b.error(name, fmt.Errorf("unsupported type %s", v.Type()))
+	}
+}
+
+// fieldSize returns the encoded size of a field with a fixed size, or the
+// given natural size if its size is variable.
+func fieldSize(tg tag, natural int) int {
+	if tg.size >= 0 {
+		return tg.size
+	}
+	return natural
+}
+
+func decodeStruct(p *Parser, v reflect.Value) {
+	t := v.Type()
+
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tg := parseTag(f)
+		if tg.omit || p.err != nil {
+			continue
+		}
+
+		decodeField(p, f.Name, v, v.Field(i), tg)
+	}
+}
+
+func decodeField(p *Parser, name string, parent, v reflect.Value, tg tag) {
+	if tg.skip {
+		p.Skip(name, int(v.Type().Size()))
+		return
+	}
+
+	if v.CanAddr() && v.Addr().Type().Implements(decoderType) {
+		v.Addr().Interface().(Decoder).DecodeWire(p)
+		return
+	}
+
+	switch v.Type() {
+	case ipType:
+		v.Set(reflect.ValueOf(p.IPv4(name)))
+		return
+	case macType:
+		v.Set(reflect.ValueOf(p.MAC(name)))
+		return
+	case udpAddrType:
+		v.Set(reflect.ValueOf(net.UDPAddr{
+			IP:   p.IPv4(name + ".IP"),
+			Port: int(p.Int16(name+".Port", tg.order)),
+		}))
+		return
+	}
+
+	switch v.Kind() {
+	case reflect.Uint8:
+		v.SetUint(uint64(p.Int8(name)))
+	case reflect.Uint16:
+		v.SetUint(uint64(p.Int16(name, tg.order)))
+	case reflect.Uint32:
+		v.SetUint(uint64(p.Int32(name, tg.order)))
+	case reflect.String:
+		size, ok := decodeSize(p, name, parent, tg)
+		if !ok {
+			return
+		}
+		v.SetString(p.String(name, size))
+
+		// Anything after the NUL isn't kept, so the size field is set to
+		// what Encode writes for the string.
+		if tg.sizeOf != "" && p.err == nil {
+			parent.FieldByName(tg.sizeOf).SetUint(uint64(v.Len() + 1))
+		}
+	case reflect.Slice:
+		if tg.rest {
+			if d := p.Rest(name); d != nil {
+				v.Set(reflect.ValueOf(d).Convert(v.Type()))
+			}
+			return
+		}
+
+		n, ok := decodeSize(p, name, parent, tg)
+		if !ok {
+			return
+		}
+		used, ok := decodeCount(p, name, parent, tg, n)
+		if !ok {
+			return
+		}
+
+		if v.Type().Elem() == byteType {
+			d := p.Bytes(name, n)
+			if d == nil {
+				return
+			}
+			if used > len(d) {
+				// A lenient parser returns what it could of a short message.
+				used = len(d)
+			}
+			v.Set(reflect.ValueOf(d[:used]).Convert(v.Type()))
+			return
+		}
+
+		s := reflect.MakeSlice(v.Type(), n, n)
+		for i := 0; i < n && p.err == nil; i++ {
+			decodeField(p, name, parent, s.Index(i), tag{order: tg.order, size: -1, max: -1})
+		}
+		v.Set(s.Slice(0, used))
+	case reflect.Array:
+		for i := 0; i < v.Len() && p.err == nil; i++ {
+			decodeField(p, name, parent, v.Index(i), tag{order: tg.order, size: -1, max: -1})
+		}
+	case reflect.Struct:
+		decodeStruct(p, v)
+	default:
+		p.error(name, fmt.Errorf("unsupported type %s", v.Type()))
+	}
+}
+
+// decodeSize returns the size of a variable-length field.
+func decodeSize(p *Parser, name string, parent reflect.Value, tg tag) (int, bool) {
+	n := tg.size
+	if tg.sizeOf != "" {
+		size := parent.FieldByName(tg.sizeOf)
+		if !size.IsValid() {
+			panic(fmt.Sprintf("wire: size field %s of %s not found", tg.sizeOf, name))
+		}
+		n = int(size.Uint())
+	}
+
+	if n < 0 {
+		p.error(name, errors.New("field has no size"))
+		return 0, false
+	}
+	if tg.max >= 0 && n > tg.max {
+		p.error(name, fmt.Errorf("size %d is more than %d", n, tg.max))
+		return 0, false
+	}
+
+	return n, true
+}
+
+// decodeCount returns the number of elements used in a slice of size n.
+func decodeCount(p *Parser, name string, parent reflect.Value, tg tag, n int) (int, bool) {
+	if tg.count == "" {
+		return n, true
+	}
+
+	count := parent.FieldByName(tg.count)
+	if !count.IsValid() {
+		panic(fmt.Sprintf("wire: count field %s of %s not found", tg.count, name))
+	}
+
+	used := int(count.Uint())
+	if used > n {
+		p.error(name, fmt.Errorf("count %d is more than %d", used, n))
+		return 0, false
+	}
+
+	return used, true
+}
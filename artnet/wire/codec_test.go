@@ -0,0 +1,96 @@
+package wire
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type sized struct {
+	Length uint8
+	Count  uint16
+	Text   string  `wire:"size=Length"`
+	Items  []uint8 `wire:"size=4,count=Count"`
+}
+
+func TestEncodeSizes(t *testing.T) {
+	v := &sized{Length: 99, Count: 99, Text: "hi", Items: []uint8{7, 8}}
+	orig := *v
+
+	buf := bytes.Buffer{}
+	if err := Encode(&buf, v); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []byte{3, 0, 2, 'h', 'i', 0, 7, 8, 0, 0}
+	if !bytes.Equal(buf.Bytes(), want) {
+		t.Errorf("encoded %x, want %x", buf.Bytes(), want)
+	}
+	if !reflect.DeepEqual(*v, orig) {
+		t.Errorf("Encode changed the value to %+v", *v)
+	}
+
+	got := &sized{}
+	if err := Decode(bytes.NewBuffer(buf.Bytes()), got); err != nil {
+		t.Fatal(err)
+	}
+	if got.Length != 3 || got.Count != 2 || got.Text != "hi" || !bytes.Equal(got.Items, v.Items) {
+		t.Errorf("decoded %+v", got)
+	}
+}
+
+func TestDecodeStringSize(t *testing.T) {
+	got := &sized{}
+	data := []byte{6, 0, 0, 'h', 'i', 0, 'x', 0, 0, 0, 0, 0, 0}
+	if err := Decode(bytes.NewBuffer(data), got); err != nil {
+		t.Fatal(err)
+	}
+	if got.Length != 3 || got.Text != "hi" {
+		t.Errorf("decoded Length %d and Text %q, want 3 and \"hi\"", got.Length, got.Text)
+	}
+}
+
+func TestEncodeSizeOverflow(t *testing.T) {
+	v := &sized{Text: strings.Repeat("x", 255)}
+
+	err := Encode(&bytes.Buffer{}, v)
+	var ferr *FieldError
+	if !errors.As(err, &ferr) || ferr.Field != "Length" {
+		t.Errorf("error is %v, want a FieldError for Length", err)
+	}
+}
+
+func TestEncodeMax(t *testing.T) {
+	type limited struct {
+		Length uint16
+		Text   string  `wire:"size=Length,max=8"`
+		Items  []uint8 `wire:"max=2"`
+	}
+
+	tests := []struct {
+		name  string
+		v     *limited
+		field string
+	}{
+		{"string at the limit", &limited{Text: "1234567"}, ""},
+		{"string over the limit", &limited{Text: "12345678"}, "Text"},
+		{"slice over the limit", &limited{Items: []uint8{1, 2, 3}}, "Items"},
+	}
+
+	for _, tt := range tests {
+		err := Encode(&bytes.Buffer{}, tt.v)
+		if tt.field == "" {
+			if err != nil {
+				t.Errorf("%s: %v", tt.name, err)
+			}
+			continue
+		}
+
+		var ferr *FieldError
+		if !errors.As(err, &ferr) || ferr.Field != tt.field {
+			t.Errorf("%s: error is %v, want a FieldError for %s", tt.name, err, tt.field)
+		}
+	}
+}
@@ -18,10 +18,47 @@ func (e FieldError) Error() string {
 	return fmt.Sprintf("field %q: %v", e.Field, e.Err)
 }
 
+// Unwrap returns the cause of the error.
+func (e FieldError) Unwrap() error {
+	return e.Err
+}
+
+// Mode controls how a Parser treats messages which don't match the layout
+// being parsed.
+type Mode uint8
+
+const (
+	// Strict rejects messages which are truncated, which have data left
+	// over, or whose strings are not terminated.
+	Strict Mode = iota
+
+	// Lenient accepts truncated messages, leaving the fields which are
+	// missing at their zero values, and ignores data left over. Sizes which
+	// are out of range are still rejected.
+	Lenient
+)
+
+func (m Mode) String() string {
+	switch m {
+	case Strict:
+		return "strict"
+	case Lenient:
+		return "lenient"
+	default:
+		return fmt.Sprintf("Mode(%d)", uint8(m))
+	}
+}
+
+// Parse creates a strict Parser.
 func Parse(r Reader) *Parser {
 	return &Parser{Reader: r}
 }
 
+// ParseMode creates a Parser with the given mode.
+func ParseMode(r Reader, mode Mode) *Parser {
+	return &Parser{Reader: r, Mode: mode}
+}
+
 type Reader interface {
 	io.Reader
 	io.ByteReader
@@ -31,6 +68,7 @@ type Reader interface {
 
 type Parser struct {
 	Reader Reader
+	Mode   Mode
 	err    error
 }
 
@@ -46,21 +84,46 @@ func (p *Parser) error(name string, err error) {
 	p.err = &FieldError{Field: name, Err: err}
 }
 
+// Fail records an error for the named field, as if reading it had failed.
+func (p *Parser) Fail(name string, err error) {
+	p.error(name, err)
+}
+
+// read returns the next n bytes of the message, or nil if the parser has
+// failed or the message is too short. The bytes are only valid until the
+// next read.
+func (p *Parser) read(name string, n int) []byte {
+	if p.err != nil {
+		return nil
+	}
+	if n < 0 {
+		p.error(name, fmt.Errorf("invalid size %d", n))
+		return nil
+	}
+
+	b := p.Reader.Next(n)
+	if len(b) < n {
+		if p.Mode == Strict {
+			p.error(name, io.ErrUnexpectedEOF)
+		}
+		return nil
+	}
+
+	return b
+}
+
 func (p *Parser) Int8(name string) uint8 {
-	b, err := p.Reader.ReadByte()
-	if err != nil {
-		p.error(name, err)
+	b := p.read(name, 1)
+	if b == nil {
 		return 0
 	}
 
-	return uint8(b)
+	return b[0]
 }
 
 func (p *Parser) Int16(name string, ord binary.ByteOrder) uint16 {
-	b := []byte{0, 0}
-	_, err := p.Reader.Read(b)
-	if err != nil {
-		p.error(name, err)
+	b := p.read(name, 2)
+	if b == nil {
 		return 0
 	}
 
@@ -68,53 +131,97 @@ func (p *Parser) Int16(name string, ord binary.ByteOrder) uint16 {
 }
 
 func (p *Parser) Int32(name string, ord binary.ByteOrder) uint32 {
-	b := []byte{0, 0, 0, 0}
-	_, err := p.Reader.Read(b)
-	if err != nil {
-		p.error(name, err)
+	b := p.read(name, 4)
+	if b == nil {
 		return 0
 	}
 
 	return ord.Uint32(b)
 }
 
+// String reads a NUL-terminated string from a field of the given capacity.
+// In lenient mode, a string filling the field without a NUL is accepted.
 func (p *Parser) String(name string, capacity int) string {
-	d := make([]byte, capacity)
-	if _, err := p.Reader.Read(d); err != nil {
-		p.error(name, err)
+	d := p.read(name, capacity)
+	if d == nil {
 		return ""
 	}
 
 	end := bytes.IndexByte(d, 0)
 	if end < 0 {
-		p.error(name, errors.New("terminating NUL not found"))
+		if p.Mode == Strict {
+			p.error(name, errors.New("terminating NUL not found"))
+			return ""
+		}
+		end = len(d)
 	}
 
 	return string(d[:end])
 }
 
 func (p *Parser) IPv4(name string) net.IP {
-	b := []byte{0, 0, 0, 0}
-	_, err := p.Reader.Read(b)
-	if err != nil {
-		p.error(name, err)
+	b := p.read(name, 4)
+	if b == nil {
 		return nil
 	}
 
-	return net.IP(b)
+	return net.IP(append([]byte(nil), b...))
 }
 
 func (p *Parser) MAC(name string) net.HardwareAddr {
-	b := []byte{0, 0, 0, 0, 0, 0}
-	_, err := p.Reader.Read(b)
+	b := p.read(name, 6)
+	if b == nil {
+		return nil
+	}
+
+	return net.HardwareAddr(append([]byte(nil), b...))
+}
+
+// Bytes reads n bytes. In lenient mode, fewer bytes are returned if the
+// message is shorter.
+func (p *Parser) Bytes(name string, n int) []byte {
+	if p.Mode == Lenient && n >= 0 && p.err == nil {
+		return append([]byte(nil), p.Reader.Next(n)...)
+	}
+
+	b := p.read(name, n)
+	if b == nil {
+		return nil
+	}
+
+	return append([]byte(nil), b...)
+}
+
+// Rest reads the remainder of the message.
+func (p *Parser) Rest(name string) []byte {
+	if p.err != nil {
+		return nil
+	}
+
+	b, err := io.ReadAll(p.Reader)
 	if err != nil {
 		p.error(name, err)
 		return nil
 	}
 
-	return net.HardwareAddr(b)
+	return b
 }
 
 func (p *Parser) Skip(name string, n int) {
-	p.Reader.Next(n)
+	p.read(name, n)
 }
+
+// End checks that the whole message has been read. In strict mode, data
+// left over is an error for the named message.
+func (p *Parser) End(name string) error {
+	if p.err == nil && p.Mode == Strict {
+		if rest := p.Reader.Next(maxMessage); len(rest) > 0 {
+			p.error(name, fmt.Errorf("%d unexpected bytes after the end of the message", len(rest)))
+		}
+	}
+
+	return p.err
+}
+
+// maxMessage is larger than any message a Parser reads.
+const maxMessage = 1 << 16
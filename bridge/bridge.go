@@ -0,0 +1,442 @@
+// Package bridge forwards DMX data between Art-Net and E1.31 (sACN).
+package bridge
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"time"
+
+	"lyra.codes/blinken/artnet"
+	"lyra.codes/blinken/dmx"
+	"lyra.codes/blinken/sacn"
+)
+
+const (
+	// DefaultTimeout is how long a stream may go silent before the bridge
+	// considers its source lost. It matches the E1.31 network data loss timeout.
+	DefaultTimeout = 2500 * time.Millisecond
+
+	// DefaultKeepAlive is how often the bridge resends unchanged data.
+	DefaultKeepAlive = time.Second
+
+	// artSyncTimeout is how long Art-Net nodes stay in synchronous mode
+	// after the last ArtSync they receive.
+	artSyncTimeout = 4 * time.Second
+
+	// terminationPackets is the number of stream-terminated packets sent
+	// when an E1.31 output stops.
+	terminationPackets = 3
+)
+
+// Direction is the direction in which a Route forwards data.
+type Direction uint8
+
+const (
+	// ArtNetToSACN receives OpDMX and sends E1.31 data.
+	ArtNetToSACN Direction = iota
+	// SACNToArtNet receives E1.31 data and sends OpDMX.
+	SACNToArtNet
+)
+
+func (d Direction) String() string {
+	switch d {
+	case ArtNetToSACN:
+		return "Art-Net → sACN"
+	case SACNToArtNet:
+		return "sACN → Art-Net"
+	default:
+		return fmt.Sprintf("Direction(%d)", uint8(d))
+	}
+}
+
+// Route maps an Art-Net port-address to an E1.31 universe.
+type Route struct {
+	ArtNet    artnet.Address
+	SACN      sacn.Universe
+	Direction Direction
+
+	// Priority is the E1.31 priority of data sent by an ArtNetToSACN route.
+	// Zero means sacn.DefaultPriority.
+	Priority uint8
+}
+
+func (r Route) String() string {
+	return fmt.Sprintf("%s %s %d", r.ArtNet, r.Direction, r.SACN)
+}
+
+// Config configures a Bridge.
+type Config struct {
+	Routes []Route
+
+	// SourceName and CID identify the bridge as an E1.31 source.
+	SourceName string
+	CID        sacn.CID
+
+	// SyncUniverse is the E1.31 synchronization address that ArtSync is
+	// translated to and from. Zero disables synchronization.
+	SyncUniverse sacn.Universe
+
+	// ArtNetDestination is where OpDMX and OpSync are sent. Defaults to
+	// artnet.Broadcast.
+	ArtNetDestination *net.UDPAddr
+
+	// Merge is how OpDMX from two controllers sending to the same routed
+	// port-address is merged, as a node does.
+	Merge artnet.MergeMode
+
+	Timeout   time.Duration
+	KeepAlive time.Duration
+}
+
+// Bridge forwards DMX data between an Art-Net and an E1.31 transport.
+type Bridge struct {
+	artnet artnet.Transport
+	sacn   sacn.Transport
+	config Config
+
+	toSACN   map[artnet.Address]*stream
+	toArtNet map[sacn.Universe]*stream
+	merger   *artnet.Merger
+
+	artSync  time.Time
+	syncSeq  uint8
+	syncWait map[sacn.Universe]bool
+}
+
+// stream is the state of one Route.
+type stream struct {
+	route Route
+
+	// Input state. An Art-Net stream follows the sequence of each
+	// controller it merges, by IP.
+	alive    bool
+	received time.Time
+	seq      uint8
+	seqs     map[string]uint8
+	source   sacn.CID
+	priority uint8
+	data     dmx.Universe
+
+	// Output state
+	sent    time.Time
+	outSeq  uint8
+	syncing bool
+}
+
+// New creates a Bridge, validating its routes.
+func New(a artnet.Transport, s sacn.Transport, config Config) (*Bridge, error) {
+	if config.SourceName == "" {
+		config.SourceName = "blinken bridge"
+	}
+	if config.CID == (sacn.CID{}) {
+		config.CID = sacn.NewCID()
+	}
+	if config.ArtNetDestination == nil {
+		config.ArtNetDestination = artnet.Broadcast
+	}
+	if config.Timeout <= 0 {
+		config.Timeout = DefaultTimeout
+	}
+	if config.KeepAlive <= 0 {
+		config.KeepAlive = DefaultKeepAlive
+	}
+	if config.SyncUniverse != 0 && !config.SyncUniverse.Valid() {
+		return nil, fmt.Errorf("invalid sync universe %d", config.SyncUniverse)
+	}
+
+	b := &Bridge{
+		artnet:   a,
+		sacn:     s,
+		config:   config,
+		toSACN:   make(map[artnet.Address]*stream),
+		toArtNet: make(map[sacn.Universe]*stream),
+		merger:   artnet.NewMerger(config.Merge),
+		syncWait: make(map[sacn.Universe]bool),
+	}
+
+	artnetOut := make(map[artnet.Address]bool)
+	sacnOut := make(map[sacn.Universe]bool)
+
+	for _, route := range config.Routes {
+		if !route.SACN.Valid() {
+			return nil, fmt.Errorf("route %s: invalid sACN universe", route)
+		}
+		if route.Priority > sacn.MaxPriority {
+			return nil, fmt.Errorf("route %s: priority %d is above %d", route, route.Priority, sacn.MaxPriority)
+		}
+		if route.Priority == 0 {
+			route.Priority = sacn.DefaultPriority
+		}
+
+		s := &stream{route: route}
+		switch route.Direction {
+		case ArtNetToSACN:
+			if b.toSACN[route.ArtNet] != nil {
+				return nil, fmt.Errorf("route %s: Art-Net address %s is already routed", route, route.ArtNet)
+			}
+			if sacnOut[route.SACN] {
+				return nil, fmt.Errorf("route %s: sACN universe %d is already an output", route, route.SACN)
+			}
+			s.seqs = make(map[string]uint8)
+			b.toSACN[route.ArtNet] = s
+			sacnOut[route.SACN] = true
+		case SACNToArtNet:
+			if b.toArtNet[route.SACN] != nil {
+				return nil, fmt.Errorf("route %s: sACN universe %d is already routed", route, route.SACN)
+			}
+			if artnetOut[route.ArtNet] {
+				return nil, fmt.Errorf("route %s: Art-Net address %s is already an output", route, route.ArtNet)
+			}
+			b.toArtNet[route.SACN] = s
+			artnetOut[route.ArtNet] = true
+		default:
+			return nil, fmt.Errorf("route %s: invalid direction", route)
+		}
+	}
+
+	// Forwarding into the same address we forward out of would loop.
+	for address := range artnetOut {
+		if b.toSACN[address] != nil {
+			return nil, fmt.Errorf("Art-Net address %s is routed in both directions", address)
+		}
+	}
+	for universe := range sacnOut {
+		if b.toArtNet[universe] != nil {
+			return nil, fmt.Errorf("sACN universe %d is routed in both directions", universe)
+		}
+	}
+
+	return b, nil
+}
+
+// Run forwards data until the context is cancelled or a transport closes.
+func (b *Bridge) Run(ctx context.Context) error {
+	sub := b.artnet.Subscribe(artnet.OpDMX, artnet.OpSync)
+	defer sub.Close()
+
+	for universe := range b.toArtNet {
+		if err := b.sacn.Join(universe); err != nil {
+			return fmt.Errorf("joining sACN universe %d: %w", universe, err)
+		}
+	}
+	if b.config.SyncUniverse != 0 && len(b.toArtNet) > 0 {
+		if err := b.sacn.Join(b.config.SyncUniverse); err != nil {
+			return fmt.Errorf("joining sACN sync universe %d: %w", b.config.SyncUniverse, err)
+		}
+	}
+
+	ticker := time.NewTicker(b.config.KeepAlive / 4)
+	defer ticker.Stop()
+
+	packets := b.sacn.Packets()
+	for {
+		select {
+		case msg, ok := <-sub.C:
+			if !ok {
+				return errors.New("Art-Net transport closed")
+			}
+			if err := b.handleArtNet(msg, time.Now()); err != nil {
+				return err
+			}
+		case msg, ok := <-packets:
+			if !ok {
+				return errors.New("sACN transport closed")
+			}
+			if err := b.handleSACN(msg, time.Now()); err != nil {
+				return err
+			}
+		case now := <-ticker.C:
+			if err := b.tick(now); err != nil {
+				return err
+			}
+		case <-ctx.Done():
+			return b.stop()
+		}
+	}
+}
+
+func (b *Bridge) handleArtNet(msg artnet.Message, now time.Time) error {
+	switch p := msg.Packet.(type) {
+	case *artnet.DMX:
+		s := b.toSACN[p.Address]
+		if s == nil {
+			return nil
+		}
+
+		var ip net.IP
+		if msg.From != nil {
+			ip = msg.From.IP
+		}
+		key := ip.String()
+		if seq, ok := s.seqs[key]; ok && s.alive && !artnet.InSequence(seq, p.Sequence) {
+			return nil
+		}
+
+		data, ok := b.merger.Merge(ip, p, now)
+		if !ok {
+			// Two other controllers are already merged into the port.
+			return nil
+		}
+
+		s.alive = true
+		s.received = now
+		s.seqs[key] = p.Sequence
+		s.data = data
+		s.syncing = b.config.SyncUniverse != 0 && now.Sub(b.artSync) < artSyncTimeout
+
+		return b.sendSACN(s, now, 0)
+	case *artnet.Sync:
+		b.artSync = now
+		if b.config.SyncUniverse == 0 {
+			return nil
+		}
+
+		b.syncSeq++
+		sync := sacn.NewSync(b.config.CID, b.syncSeq, b.config.SyncUniverse)
+		return b.sacn.Send(b.config.SyncUniverse.Multicast(), sync)
+	}
+
+	return nil
+}
+
+func (b *Bridge) handleSACN(msg sacn.Message, now time.Time) error {
+	switch p := msg.Packet.(type) {
+	case *sacn.Data:
+		s := b.toArtNet[p.Universe]
+		if s == nil || p.StartCode != 0 || sacn.OptionPreview.Enabled(p.Options) {
+			return nil
+		}
+
+		if s.alive && p.CID != s.source {
+			// Another source is sending to the same universe; follow the
+			// highest priority one.
+			if p.Priority <= s.priority {
+				return nil
+			}
+		} else if s.alive && !sacn.InSequence(s.seq, p.Sequence) {
+			return nil
+		}
+
+		if sacn.OptionTerminated.Enabled(p.Options) {
+			s.alive = false
+			return nil
+		}
+
+		s.alive = true
+		s.received = now
+		s.seq = p.Sequence
+		s.source = p.CID
+		s.priority = p.Priority
+		s.data = p.Data
+		s.syncing = p.SyncAddress != 0
+		if s.syncing {
+			b.syncWait[p.SyncAddress] = true
+		}
+
+		return b.sendArtNet(s, now)
+	case *sacn.Sync:
+		if !b.syncWait[p.SyncAddress] {
+			return nil
+		}
+
+		delete(b.syncWait, p.SyncAddress)
+		return b.artnet.Send(b.config.ArtNetDestination, artnet.NewSync())
+	}
+
+	return nil
+}
+
+func (b *Bridge) tick(now time.Time) error {
+	b.merger.Expire(now)
+	for address, s := range b.toSACN {
+		if s.alive {
+			s.data = b.merger.Universe(address)
+		}
+	}
+
+	for _, s := range b.toSACN {
+		if err := b.refresh(s, now, b.sendSACN); err != nil {
+			return err
+		}
+	}
+	for _, s := range b.toArtNet {
+		if err := b.refresh(s, now, func(s *stream, now time.Time, _ sacn.Options) error {
+			return b.sendArtNet(s, now)
+		}); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// refresh resends a stream's data if it hasn't been sent recently, and
+// stops it if its source has been lost.
+func (b *Bridge) refresh(s *stream, now time.Time, send func(*stream, time.Time, sacn.Options) error) error {
+	if !s.alive {
+		return nil
+	}
+
+	if now.Sub(s.received) > b.config.Timeout {
+		s.alive = false
+		if s.route.Direction == ArtNetToSACN {
+			s.seqs = make(map[string]uint8)
+			return b.terminate(s, now)
+		}
+		return nil
+	}
+
+	if now.Sub(s.sent) >= b.config.KeepAlive {
+		return send(s, now, 0)
+	}
+
+	return nil
+}
+
+// stop terminates all E1.31 output streams.
+func (b *Bridge) stop() error {
+	now := time.Now()
+	for _, s := range b.toSACN {
+		if s.alive {
+			s.alive = false
+			if err := b.terminate(s, now); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func (b *Bridge) terminate(s *stream, now time.Time) error {
+	for i := 0; i < terminationPackets; i++ {
+		if err := b.sendSACN(s, now, sacn.OptionTerminated); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (b *Bridge) sendSACN(s *stream, now time.Time, options sacn.Options) error {
+	s.outSeq++
+	s.sent = now
+
+	p := sacn.NewData(b.config.CID, b.config.SourceName, s.route.SACN, s.outSeq, s.data)
+	p.Priority = s.route.Priority
+	p.Options = options
+	if s.syncing {
+		p.SyncAddress = b.config.SyncUniverse
+	}
+
+	return b.sacn.Send(s.route.SACN.Multicast(), p)
+}
+
+func (b *Bridge) sendArtNet(s *stream, now time.Time) error {
+	s.outSeq = artnet.NextSequence(s.outSeq)
+	s.sent = now
+
+	return b.artnet.Send(b.config.ArtNetDestination, artnet.NewDMX(s.route.ArtNet, s.outSeq, s.data))
+}
@@ -0,0 +1,314 @@
+package bridge
+
+import (
+	"bytes"
+	"net"
+	"testing"
+	"time"
+
+	"lyra.codes/blinken/artnet"
+	"lyra.codes/blinken/dmx"
+	"lyra.codes/blinken/sacn"
+)
+
+// fakeArtNet records the packets a Bridge sends over Art-Net.
+type fakeArtNet struct {
+	sent []artnet.Packet
+}
+
+func (t *fakeArtNet) Send(to *net.UDPAddr, packet artnet.Packet) error {
+	t.sent = append(t.sent, packet)
+	return nil
+}
+
+func (t *fakeArtNet) Nodes() <-chan *artnet.Node                                 { return nil }
+func (t *fakeArtNet) Subscribe(ops ...artnet.Operation) *artnet.Subscription     { return nil }
+func (t *fakeArtNet) Diagnostics(artnet.DiagnosticPriority) *artnet.Subscription { return nil }
+func (t *fakeArtNet) Triggers(oem uint16) *artnet.Subscription                   { return nil }
+func (t *fakeArtNet) Universes(addresses ...artnet.Address) *artnet.Subscription { return nil }
+
+// fakeSACN records the packets a Bridge sends over E1.31.
+type fakeSACN struct {
+	sent []sacn.Packet
+}
+
+func (t *fakeSACN) Send(to *net.UDPAddr, packet sacn.Packet) error {
+	t.sent = append(t.sent, packet)
+	return nil
+}
+
+func (t *fakeSACN) Join(universe sacn.Universe) error  { return nil }
+func (t *fakeSACN) Leave(universe sacn.Universe) error { return nil }
+func (t *fakeSACN) Packets() <-chan sacn.Message       { return nil }
+
+var (
+	testStart = time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	sourceA   = sacn.CID{0xA}
+	sourceB   = sacn.CID{0xB}
+)
+
+func newTestBridge(t *testing.T, config Config) (*Bridge, *fakeArtNet, *fakeSACN) {
+	t.Helper()
+
+	a, s := &fakeArtNet{}, &fakeSACN{}
+	b, err := New(a, s, config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b, a, s
+}
+
+func artDMX(seq uint8, level dmx.Channel) artnet.Message {
+	return artnet.Message{Packet: artnet.NewDMX(1, seq, dmx.Universe{level, level})}
+}
+
+func sacnData(cid sacn.CID, seq, priority uint8, level dmx.Channel) sacn.Message {
+	p := sacn.NewData(cid, "test", 1, seq, dmx.Universe{level, level})
+	p.Priority = priority
+	return sacn.Message{Packet: p}
+}
+
+func TestArtNetSequence(t *testing.T) {
+	b, _, s := newTestBridge(t, Config{Routes: []Route{{ArtNet: 1, SACN: 1}}})
+
+	tests := []struct {
+		seq     uint8
+		forward bool
+	}{
+		{10, true},
+		{11, true},
+		{9, false},
+		{11, false},
+		{0, true},
+		{12, true},
+		{200, true},
+		{190, false},
+	}
+
+	for i, tt := range tests {
+		before := len(s.sent)
+		if err := b.handleArtNet(artDMX(tt.seq, dmx.Channel(i)), testStart); err != nil {
+			t.Fatal(err)
+		}
+		if forwarded := len(s.sent) > before; forwarded != tt.forward {
+			t.Errorf("sequence %d after %v: forwarded %v, want %v", tt.seq, tests[:i], forwarded, tt.forward)
+		}
+	}
+}
+
+func TestArtNetMerge(t *testing.T) {
+	tests := []struct {
+		mode artnet.MergeMode
+		want dmx.Universe
+	}{
+		{artnet.MergeHTP, dmx.Universe{200, 100}},
+		{artnet.MergeLTP, dmx.Universe{10, 100}},
+	}
+
+	for _, tt := range tests {
+		b, _, s := newTestBridge(t, Config{Routes: []Route{{ArtNet: 1, SACN: 1}}, Merge: tt.mode})
+		a := &net.UDPAddr{IP: net.IPv4(2, 0, 0, 1), Port: artnet.Port}
+		c := &net.UDPAddr{IP: net.IPv4(2, 0, 0, 2), Port: artnet.Port}
+
+		// Each controller keeps its own sequence.
+		msgs := []artnet.Message{
+			{From: a, Packet: artnet.NewDMX(1, 10, dmx.Universe{200, 0})},
+			{From: c, Packet: artnet.NewDMX(1, 1, dmx.Universe{10, 100})},
+		}
+		for _, msg := range msgs {
+			if err := b.handleArtNet(msg, testStart); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		if len(s.sent) != 2 {
+			t.Fatalf("%d: sent %d packets, want 2", tt.mode, len(s.sent))
+		}
+		if got := s.sent[1].(*sacn.Data).Data; !bytes.Equal(got, tt.want) {
+			t.Errorf("%d: merged %v, want %v", tt.mode, got, tt.want)
+		}
+	}
+}
+
+func TestSACNSequenceAndPriority(t *testing.T) {
+	b, a, _ := newTestBridge(t, Config{Routes: []Route{{ArtNet: 1, SACN: 1, Direction: SACNToArtNet}}})
+
+	tests := []struct {
+		name    string
+		msg     sacn.Message
+		forward bool
+	}{
+		{"first packet", sacnData(sourceA, 1, 100, 1), true},
+		{"next in sequence", sacnData(sourceA, 2, 100, 2), true},
+		{"out of order", sacnData(sourceA, 1, 100, 3), false},
+		{"other source at lower priority", sacnData(sourceB, 1, 50, 4), false},
+		{"other source at the same priority", sacnData(sourceB, 1, 100, 5), false},
+		{"other source at higher priority", sacnData(sourceB, 1, 150, 6), true},
+		{"first source after takeover", sacnData(sourceA, 3, 100, 7), false},
+	}
+
+	for _, tt := range tests {
+		before := len(a.sent)
+		if err := b.handleSACN(tt.msg, testStart); err != nil {
+			t.Fatal(err)
+		}
+		if forwarded := len(a.sent) > before; forwarded != tt.forward {
+			t.Errorf("%s: forwarded %v, want %v", tt.name, forwarded, tt.forward)
+		}
+	}
+
+	last := a.sent[len(a.sent)-1].(*artnet.DMX)
+	if last.Data[0] != 6 {
+		t.Errorf("last forwarded level is %d, want 6", last.Data[0])
+	}
+}
+
+func TestSourceTimeout(t *testing.T) {
+	b, _, s := newTestBridge(t, Config{
+		Routes:    []Route{{ArtNet: 1, SACN: 1}},
+		Timeout:   time.Second,
+		KeepAlive: 400 * time.Millisecond,
+	})
+
+	if err := b.handleArtNet(artDMX(1, 9), testStart); err != nil {
+		t.Fatal(err)
+	}
+
+	// A keep-alive is sent while the source is quiet but not lost.
+	if err := b.tick(testStart.Add(500 * time.Millisecond)); err != nil {
+		t.Fatal(err)
+	}
+	if len(s.sent) != 2 {
+		t.Fatalf("sent %d packets before the timeout, want 2", len(s.sent))
+	}
+	if p := s.sent[1].(*sacn.Data); sacn.OptionTerminated.Enabled(p.Options) || p.Data[0] != 9 {
+		t.Errorf("keep-alive is %+v", p)
+	}
+
+	if err := b.tick(testStart.Add(1100 * time.Millisecond)); err != nil {
+		t.Fatal(err)
+	}
+	if len(s.sent) != 2+terminationPackets {
+		t.Fatalf("sent %d packets after the timeout, want %d", len(s.sent), 2+terminationPackets)
+	}
+	for _, p := range s.sent[2:] {
+		if !sacn.OptionTerminated.Enabled(p.(*sacn.Data).Options) {
+			t.Errorf("packet after the timeout isn't terminated: %+v", p)
+		}
+	}
+
+	// Nothing more is sent for a lost source.
+	if err := b.tick(testStart.Add(3 * time.Second)); err != nil {
+		t.Fatal(err)
+	}
+	if len(s.sent) != 2+terminationPackets {
+		t.Errorf("sent %d packets after the source was lost", len(s.sent)-2-terminationPackets)
+	}
+}
+
+func TestSACNTermination(t *testing.T) {
+	b, a, _ := newTestBridge(t, Config{Routes: []Route{{ArtNet: 1, SACN: 1, Direction: SACNToArtNet}}})
+
+	if err := b.handleSACN(sacnData(sourceA, 1, 100, 1), testStart); err != nil {
+		t.Fatal(err)
+	}
+
+	terminated := sacnData(sourceA, 2, 100, 1)
+	sacn.OptionTerminated.Set(&terminated.Packet.(*sacn.Data).Options)
+	if err := b.handleSACN(terminated, testStart); err != nil {
+		t.Fatal(err)
+	}
+
+	// A lower priority source can take over once the first has stopped.
+	if err := b.handleSACN(sacnData(sourceB, 1, 50, 2), testStart); err != nil {
+		t.Fatal(err)
+	}
+	if len(a.sent) != 2 {
+		t.Errorf("sent %d packets, want 2", len(a.sent))
+	}
+}
+
+func TestArtSyncForwarding(t *testing.T) {
+	b, _, s := newTestBridge(t, Config{
+		Routes:       []Route{{ArtNet: 1, SACN: 1}},
+		SyncUniverse: 9,
+	})
+
+	// Data before any ArtSync isn't synchronized.
+	if err := b.handleArtNet(artDMX(1, 1), testStart); err != nil {
+		t.Fatal(err)
+	}
+	if p := s.sent[0].(*sacn.Data); p.SyncAddress != 0 {
+		t.Errorf("unsynchronized data has sync address %d", p.SyncAddress)
+	}
+
+	if err := b.handleArtNet(artnet.Message{Packet: artnet.NewSync()}, testStart); err != nil {
+		t.Fatal(err)
+	}
+	sync, ok := s.sent[1].(*sacn.Sync)
+	if !ok || sync.SyncAddress != 9 {
+		t.Fatalf("ArtSync was sent as %+v", s.sent[1])
+	}
+
+	if err := b.handleArtNet(artDMX(2, 2), testStart.Add(time.Second)); err != nil {
+		t.Fatal(err)
+	}
+	if p := s.sent[2].(*sacn.Data); p.SyncAddress != 9 {
+		t.Errorf("data after ArtSync has sync address %d, want 9", p.SyncAddress)
+	}
+
+	// Nodes leave synchronous mode when ArtSync stops.
+	if err := b.handleArtNet(artDMX(3, 3), testStart.Add(artSyncTimeout+time.Second)); err != nil {
+		t.Fatal(err)
+	}
+	if p := s.sent[3].(*sacn.Data); p.SyncAddress != 0 {
+		t.Errorf("data after ArtSync stopped has sync address %d", p.SyncAddress)
+	}
+}
+
+func TestSACNSyncForwarding(t *testing.T) {
+	b, a, _ := newTestBridge(t, Config{
+		Routes:       []Route{{ArtNet: 1, SACN: 1, Direction: SACNToArtNet}},
+		SyncUniverse: 9,
+	})
+
+	sync := sacn.Message{Packet: sacn.NewSync(sourceA, 1, 9)}
+
+	// A sync packet without synchronized data waiting isn't forwarded.
+	if err := b.handleSACN(sync, testStart); err != nil {
+		t.Fatal(err)
+	}
+	if len(a.sent) != 0 {
+		t.Fatalf("forwarded %d packets, want none", len(a.sent))
+	}
+
+	data := sacnData(sourceA, 1, 100, 1)
+	data.Packet.(*sacn.Data).SyncAddress = 9
+	if err := b.handleSACN(data, testStart); err != nil {
+		t.Fatal(err)
+	}
+	if err := b.handleSACN(sync, testStart); err != nil {
+		t.Fatal(err)
+	}
+	if len(a.sent) != 2 {
+		t.Fatalf("sent %d packets, want 2", len(a.sent))
+	}
+	if _, ok := a.sent[1].(*artnet.Sync); !ok {
+		t.Errorf("sync was forwarded as %T", a.sent[1])
+	}
+
+	// Each sync releases the data sent before it only once.
+	if err := b.handleSACN(sync, testStart); err != nil {
+		t.Fatal(err)
+	}
+	if len(a.sent) != 2 {
+		t.Errorf("a repeated sync was forwarded")
+	}
+}
+
+func TestNewRejectsPriority(t *testing.T) {
+	_, err := New(&fakeArtNet{}, &fakeSACN{}, Config{Routes: []Route{{ArtNet: 1, SACN: 1, Priority: 201}}})
+	if err == nil {
+		t.Error("New accepted priority 201")
+	}
+}
@@ -0,0 +1,160 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+
+	"lyra.codes/blinken/artnet"
+	"lyra.codes/blinken/bridge"
+	"lyra.codes/blinken/sacn"
+)
+
+// routeFlags collects repeated -route flags.
+type routeFlags struct {
+	routes []bridge.Route
+}
+
+func (f *routeFlags) String() string {
+	parts := make([]string, 0, len(f.routes))
+	for _, r := range f.routes {
+		parts = append(parts, r.String())
+	}
+	return strings.Join(parts, ", ")
+}
+
+// Set parses a route written as "artnet>sacn" or "sacn>artnet", where the
+// Art-Net side is a port-address like 0:0.1 and the sACN side is prefixed
+// with "e" like e1. A priority may follow the sACN universe after a "@".
+func (f *routeFlags) Set(s string) error {
+	parts := strings.Split(s, ">")
+	if len(parts) != 2 {
+		return fmt.Errorf("route %q must be written as from>to", s)
+	}
+
+	route := bridge.Route{}
+	from, to := parts[0], parts[1]
+
+	if strings.HasPrefix(from, "e") {
+		route.Direction = bridge.SACNToArtNet
+		from, to = to, from
+	} else if !strings.HasPrefix(to, "e") {
+		return fmt.Errorf("route %q must have one sACN universe, like e1", s)
+	}
+
+	address, err := artnet.ParseAddress(from)
+	if err != nil {
+		return err
+	}
+	route.ArtNet = address
+
+	universe := strings.TrimPrefix(to, "e")
+	if at := strings.IndexByte(universe, '@'); at >= 0 {
+		priority, err := parsePriority(universe[at+1:])
+		if err != nil {
+			return fmt.Errorf("route %q: %v", s, err)
+		}
+		route.Priority = priority
+		universe = universe[:at]
+	}
+
+	u, err := strconv.ParseUint(universe, 10, 16)
+	if err != nil {
+		return fmt.Errorf("invalid sACN universe in route %q", s)
+	}
+	route.SACN = sacn.Universe(u)
+
+	f.routes = append(f.routes, route)
+	return nil
+}
+
+// parsePriority parses an E1.31 priority, which is from 0 to 200.
+func parsePriority(s string) (uint8, error) {
+	priority, err := strconv.ParseUint(s, 10, 8)
+	if err != nil || priority > uint64(sacn.MaxPriority) {
+		return 0, fmt.Errorf("priority %q is not from %d to %d", s, sacn.MinPriority, sacn.MaxPriority)
+	}
+	return uint8(priority), nil
+}
+
+func runBridge(ctx context.Context, args []string) error {
+	flags := flag.NewFlagSet("bridge", flag.ContinueOnError)
+	priority := flags.String("priority", strconv.Itoa(int(sacn.DefaultPriority)), "default E1.31 priority for routes to sACN, from 0 to 200")
+	routes := &routeFlags{}
+	flags.Var(routes, "route", "route to bridge, like 0:0.1>e1, 0:0.2>e2@150 or e3>0:0.3 (repeatable)")
+	name := flags.String("name", "blinken bridge", "E1.31 source name")
+	syncUniverse := flags.Uint("sync", 0, "E1.31 synchronization universe for ArtSync (0 disables)")
+	dest := flags.String("dest", "", "Art-Net destination address (default broadcast)")
+	iface := flags.String("interface", "", "network interface for sACN multicast")
+	merge := flags.String("merge", "htp", "how to merge two Art-Net controllers on one port-address: htp or ltp")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if len(routes.routes) == 0 {
+		return fmt.Errorf("at least one -route is required")
+	}
+	defaultPriority, err := parsePriority(*priority)
+	if err != nil {
+		return fmt.Errorf("-priority: %v", err)
+	}
+
+	for i := range routes.routes {
+		if routes.routes[i].Priority == 0 {
+			routes.routes[i].Priority = defaultPriority
+		}
+	}
+
+	config := bridge.Config{
+		Routes:       routes.routes,
+		SourceName:   *name,
+		SyncUniverse: sacn.Universe(*syncUniverse),
+	}
+
+	switch strings.ToLower(*merge) {
+	case "htp":
+		config.Merge = artnet.MergeHTP
+	case "ltp":
+		config.Merge = artnet.MergeLTP
+	default:
+		return fmt.Errorf("-merge must be htp or ltp, not %q", *merge)
+	}
+
+	if *dest != "" {
+		addr, err := net.ResolveUDPAddr("udp4", net.JoinHostPort(*dest, strconv.Itoa(artnet.Port)))
+		if err != nil {
+			return err
+		}
+		config.ArtNetDestination = addr
+	}
+
+	var ifi *net.Interface
+	if *iface != "" {
+		i, err := net.InterfaceByName(*iface)
+		if err != nil {
+			return err
+		}
+		ifi = i
+	}
+
+	a, err := artnet.Listen(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("listening for Art-Net: %w", err)
+	}
+	s, err := sacn.Listen(ctx, ifi)
+	if err != nil {
+		return fmt.Errorf("listening for sACN: %w", err)
+	}
+
+	b, err := bridge.New(a, s, config)
+	if err != nil {
+		return err
+	}
+
+	for _, route := range config.Routes {
+		fmt.Printf("Bridging %s\n", route)
+	}
+	return b.Run(ctx)
+}
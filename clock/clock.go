@@ -0,0 +1,23 @@
+// Package clock provides show clocks that drive animations.
+package clock
+
+import "time"
+
+// Clock reports the current position in a show.
+type Clock interface {
+	Now() time.Duration
+}
+
+// Wall is a Clock driven by wall time, starting from when it was created.
+type Wall struct {
+	start time.Time
+}
+
+// NewWall creates a Wall clock starting at zero.
+func NewWall() *Wall {
+	return &Wall{start: time.Now()}
+}
+
+func (c *Wall) Now() time.Duration {
+	return time.Since(c.start)
+}
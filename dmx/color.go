@@ -1,61 +1,34 @@
 package dmx
 
 import (
-	"fmt"
-
 	"lyra.codes/blinken/color"
 )
 
-const (
-	channels = 4
-
-	red   = 0
-	green = 1
-	blue  = 2
-	white = 3
-)
-
 // RGBW is one or more RGBW colors as DMX channels.
 type RGBW []Channel
 
+func (c RGBW) pixels() Pixels {
+	return Pixels{Layout: LayoutRGBW, Channels: c}
+}
+
 func (c RGBW) Len() int {
-	return len(c) / channels
+	return c.pixels().Len()
 }
 
 func (c RGBW) At(index int) color.RGBW {
-	return color.RGBW{
-		byteToFloat(c[index*channels+red]),
-		byteToFloat(c[index*channels+green]),
-		byteToFloat(c[index*channels+blue]),
-		byteToFloat(c[index*channels+white]),
-	}
+	return c.pixels().At(index)
 }
 
 func (c RGBW) Set(index int, d color.RGBW) {
-	c[index*channels+red] = floatToByte(d.R)
-	c[index*channels+green] = floatToByte(d.G)
-	c[index*channels+blue] = floatToByte(d.B)
-	c[index*channels+white] = floatToByte(d.W)
+	c.pixels().Set(index, d)
 }
 
 func (c RGBW) Spread(startIndex int, colors []color.RGBW) {
-	for i, color := range colors {
-		c.Set(startIndex+i, color)
-	}
+	c.pixels().Spread(startIndex, colors)
 }
 
 func (c RGBW) Inspect() []string {
-	l := c.Len()
-	out := make([]string, 0, l)
-
-	for i := 0; i < l; i++ {
-		out = append(
-			out,
-			fmt.Sprintf("(%03d, %03d, %03d, %03d)", c[i*channels+red], c[i*channels+green], c[i*channels+blue], c[i*channels+white]),
-		)
-	}
-
-	return out
+	return c.pixels().Inspect()
 }
 
 func byteToFloat(b Channel) float64 {
@@ -65,3 +38,12 @@ func byteToFloat(b Channel) float64 {
 func floatToByte(f float64) Channel {
 	return Channel(f*255.0 + 0.5)
 }
+
+// amberLevel returns the level of an amber emitter showing a color with red
+// and green levels: the yellow in it, which is the lesser of the two.
+func amberLevel(r, g float64) float64 {
+	if r < g {
+		return r
+	}
+	return g
+}
@@ -0,0 +1,95 @@
+package dmx
+
+import "math"
+
+// Curve maps a level from 0 to 1 to the output level sent to a channel,
+// also from 0 to 1.
+type Curve interface {
+	Apply(level float64) float64
+}
+
+// CurveFunc is a function used as a Curve.
+type CurveFunc func(level float64) float64
+
+func (f CurveFunc) Apply(level float64) float64 {
+	return f(level)
+}
+
+// Output curves.
+var (
+	// Linear outputs levels unchanged.
+	Linear Curve = CurveFunc(func(level float64) float64 { return level })
+
+	// SquareLaw outputs the square of levels, like an incandescent dimmer.
+	SquareLaw Curve = CurveFunc(func(level float64) float64 { return level * level })
+
+	// CIE1931 treats levels as perceived lightness, and outputs the
+	// luminance which looks that bright.
+	CIE1931 Curve = CurveFunc(cie1931)
+)
+
+func cie1931(level float64) float64 {
+	l := level * 100
+	if l <= 8 {
+		return l / 903.3
+	}
+	return math.Pow((l+16)/116, 3)
+}
+
+// Gamma returns a curve raising levels to the power gamma.
+func Gamma(gamma float64) Curve {
+	return CurveFunc(func(level float64) float64 {
+		return math.Pow(level, gamma)
+	})
+}
+
+// Table is a curve given by a lookup table of output levels for evenly
+// spaced levels from 0 to 1. Levels between entries are interpolated. A
+// table needs at least two entries.
+type Table []float64
+
+// ChannelTable returns a table of channel values, such as a 256-entry table
+// of the value to send for each 8-bit level.
+func ChannelTable(values []Channel) Table {
+	t := make(Table, len(values))
+	for i, v := range values {
+		t[i] = byteToFloat(v)
+	}
+	return t
+}
+
+func (t Table) Apply(level float64) float64 {
+	if len(t) < 2 {
+		return level
+	}
+
+	x := ClampLevel(level) * float64(len(t)-1)
+	i := int(x)
+	if i >= len(t)-1 {
+		return t[len(t)-1]
+	}
+
+	frac := x - float64(i)
+	return t[i] + (t[i+1]-t[i])*frac
+}
+
+// Curves are the output curves of roles. Roles without curves are linear.
+type Curves map[Role]Curve
+
+// Uniform returns curves which apply a curve to intensity and every color.
+func Uniform(c Curve) Curves {
+	curves := Curves{Intensity: c}
+	for r := Red; r.Colored(); r++ {
+		curves[r] = c
+	}
+	return curves
+}
+
+// apply returns the output level for a role's level.
+func (c Curves) apply(role Role, level float64) float64 {
+	level = ClampLevel(level)
+	if curve := c[role]; curve != nil {
+		return ClampLevel(curve.Apply(level))
+	}
+	return level
+}
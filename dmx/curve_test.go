@@ -0,0 +1,124 @@
+package dmx
+
+import (
+	"bytes"
+	"math"
+	"testing"
+
+	"lyra.codes/blinken/color"
+)
+
+func TestCurves(t *testing.T) {
+	tests := []struct {
+		name  string
+		curve Curve
+		level float64
+		want  float64
+	}{
+		{"linear", Linear, 0.3, 0.3},
+		{"square law 0", SquareLaw, 0, 0},
+		{"square law 0.5", SquareLaw, 0.5, 0.25},
+		{"square law 1", SquareLaw, 1, 1},
+		{"gamma 2.2 0", Gamma(2.2), 0, 0},
+		{"gamma 2.2 0.5", Gamma(2.2), 0.5, 0.217638},
+		{"gamma 2.2 1", Gamma(2.2), 1, 1},
+		{"gamma 0.5", Gamma(0.5), 0.25, 0.5},
+		{"CIE 1931 0", CIE1931, 0, 0},
+		{"CIE 1931 linear part", CIE1931, 0.04, 4 / 903.3},
+		{"CIE 1931 at the knee", CIE1931, 0.08, 0.008856},
+		{"CIE 1931 0.5", CIE1931, 0.5, 0.184187},
+		{"CIE 1931 1", CIE1931, 1, 1},
+		{"table start", Table{0, 0.5, 1}, 0, 0},
+		{"table entry", Table{0, 0.2, 1}, 0.5, 0.2},
+		{"table between entries", Table{0, 0.2, 1}, 0.75, 0.6},
+		{"table end", Table{0, 0.2, 0.9}, 1, 0.9},
+		{"table below 0", Table{0.1, 1}, -1, 0.1},
+		{"table above 1", Table{0, 0.8}, 2, 0.8},
+		{"table of one entry", Table{0.7}, 0.3, 0.3},
+		{"channel table", ChannelTable([]Channel{0, 51, 255}), 0.25, 0.1},
+	}
+
+	for _, tt := range tests {
+		if got := tt.curve.Apply(tt.level); math.Abs(got-tt.want) > 1e-6 {
+			t.Errorf("%s: level %v is %v, want %v", tt.name, tt.level, got, tt.want)
+		}
+	}
+}
+
+func TestCurvesApply(t *testing.T) {
+	over := CurveFunc(func(level float64) float64 { return level * 2 })
+	curves := Curves{Red: SquareLaw, Green: over}
+
+	tests := []struct {
+		role  Role
+		level float64
+		want  float64
+	}{
+		{Red, 0.5, 0.25},
+		{Red, 2, 1},
+		{Green, 0.75, 1},
+		{Blue, 0.5, 0.5},
+		{Blue, -0.5, 0},
+	}
+
+	for _, tt := range tests {
+		if got := curves.apply(tt.role, tt.level); got != tt.want {
+			t.Errorf("%s %v is %v, want %v", tt.role, tt.level, got, tt.want)
+		}
+	}
+
+	uniform := Uniform(SquareLaw)
+	for _, role := range []Role{Intensity, Red, Green, Blue, White, Amber, UV} {
+		if uniform[role] == nil {
+			t.Errorf("uniform curves have none for %s", role)
+		}
+	}
+	for _, role := range []Role{Pan, Tilt, Strobe} {
+		if uniform[role] != nil {
+			t.Errorf("uniform curves have one for %s", role)
+		}
+	}
+}
+
+func TestFixtureSetCurves(t *testing.T) {
+	tests := []struct {
+		name    string
+		profile *FixtureProfile
+		curves  Curves
+		params  Params
+		want    Universe
+	}{
+		{"8-bit", ProfileRGB, Uniform(SquareLaw), Params{Red: 0.5, Green: 1}, Universe{64, 255, 0}},
+		{"16-bit", ProfileDimmer16, Uniform(SquareLaw), Params{Intensity: 0.5}, Universe{0x40, 0x00}},
+		{"one role", ProfileRGB, Curves{Blue: Gamma(2)}, Params{Red: 0.5, Blue: 0.5}, Universe{128, 0, 64}},
+		{"pan is linear", profileMover, Uniform(SquareLaw), Params{Pan: 0.5, Intensity: 0.5}, Universe{128, 128, 7, 64, 255, 9}},
+	}
+
+	for _, tt := range tests {
+		f := &Fixture{Profile: tt.profile, Address: 1, Curves: tt.curves}
+		u := make(Universe, len(tt.want))
+		if err := f.Set(u, tt.params); err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if !bytes.Equal(u, tt.want) {
+			t.Errorf("%s: universe is %v, want %v", tt.name, u, tt.want)
+		}
+	}
+}
+
+func TestPixelLayoutSetCurves(t *testing.T) {
+	rgb16 := PixelLayout{Name: "RGB16", Order: []Role{Red, Green, Blue}, Wide: true}
+	c := color.RGBW{R: 0.5, G: 1, B: 0.25}
+
+	pixel := make([]Channel, 3)
+	LayoutRGB.SetCurves(pixel, c, Uniform(SquareLaw))
+	if want := []Channel{64, 255, 16}; !bytes.Equal(pixel, want) {
+		t.Errorf("8-bit pixel is %v, want %v", pixel, want)
+	}
+
+	pixel = make([]Channel, 6)
+	rgb16.SetCurves(pixel, c, Uniform(SquareLaw))
+	if want := []Channel{0x40, 0x00, 0xff, 0xff, 0x10, 0x00}; !bytes.Equal(pixel, want) {
+		t.Errorf("16-bit pixel is %v, want %v", pixel, want)
+	}
+}
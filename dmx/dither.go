@@ -0,0 +1,41 @@
+package dmx
+
+import "math"
+
+// Dither carries the error of quantizing levels to 8-bit channels from one
+// frame to the next, so that a level between two channel values alternates
+// between them at the frame rate, and averages out to the level.
+//
+// Dither is deterministic: quantizing the same levels in the same order
+// gives the same values. Each channel's error is kept by its index, so a
+// Dither belongs to one fixture or run of pixels, and only helps when every
+// frame is rendered and sent. The zero value is ready to use, and a nil
+// *Dither quantizes without dithering.
+type Dither struct {
+	errors []float64
+}
+
+// Reset forgets the error carried for every channel.
+func (d *Dither) Reset() {
+	for i := range d.errors {
+		d.errors[i] = 0
+	}
+}
+
+// quantize returns the channel value for the level of channel i.
+func (d *Dither) quantize(i int, level float64) Channel {
+	if d == nil {
+		return floatToByte(level)
+	}
+	if i >= len(d.errors) {
+		d.errors = append(d.errors, make([]float64, i+1-len(d.errors))...)
+	}
+
+	target := level*255.0 + d.errors[i]
+	q := math.Max(0, math.Min(255, math.Floor(target+0.5)))
+
+	// Clamping at either end can leave more than half a step of error,
+	// which would otherwise build up while a channel is off or at full.
+	d.errors[i] = math.Max(-0.5, math.Min(0.5, target-q))
+	return Channel(q)
+}
@@ -0,0 +1,113 @@
+package dmx
+
+import "testing"
+
+func TestDitherAverage(t *testing.T) {
+	const frames = 100
+
+	for _, level := range []float64{0.3 / 255, 2.37 / 255, 10.5 / 255, 0.5, 1} {
+		d := &Dither{}
+		sum := 0
+		for i := 0; i < frames; i++ {
+			sum += int(d.quantize(0, level))
+		}
+
+		want := level * 255 * frames
+		if got := float64(sum); got < want-1 || got > want+1 {
+			t.Errorf("level %.4f: sum of %d frames is %v, want %.1f", level, frames, got, want)
+		}
+	}
+}
+
+func TestDitherDeterministic(t *testing.T) {
+	levels := []float64{0, 0.7 / 255, 1.2 / 255, 0.4, 1, 3.3 / 255}
+
+	run := func() []Channel {
+		d := &Dither{}
+		var out []Channel
+		for frame := 0; frame < 20; frame++ {
+			for i, level := range levels {
+				out = append(out, d.quantize(i, level))
+			}
+		}
+		return out
+	}
+
+	a, b := run(), run()
+	for i := range a {
+		if a[i] != b[i] {
+			t.Fatalf("value %d is %d then %d", i, a[i], b[i])
+		}
+	}
+}
+
+func TestDitherOff(t *testing.T) {
+	f, err := NewFixture(ProfileDimmer, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	u := make(Universe, 1)
+	for i := 0; i < 10; i++ {
+		if err := f.Set(u, Params{Intensity: 0.7 / 255}); err != nil {
+			t.Fatal(err)
+		}
+		if u[0] != 1 {
+			t.Fatalf("frame %d without dither is %d, want 1", i, u[0])
+		}
+	}
+}
+
+func TestDitherFixture(t *testing.T) {
+	f, err := NewFixture(ProfileRGB, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.Dither = &Dither{}
+
+	u := make(Universe, 3)
+	var sums [3]int
+	for i := 0; i < 4; i++ {
+		if err := f.Set(u, Params{Red: 0.25 / 255, Green: 0.5 / 255, Blue: 0.75 / 255}); err != nil {
+			t.Fatal(err)
+		}
+		for ch, v := range u {
+			sums[ch] += int(v)
+		}
+	}
+
+	if want := [3]int{1, 2, 3}; sums != want {
+		t.Errorf("sums of 4 frames are %v, want %v", sums, want)
+	}
+}
+
+func TestDitherMover(t *testing.T) {
+	f, err := NewFixture(profileMover, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.Dither = &Dither{}
+
+	params := Params{Pan: 100.5 / 255, Tilt: 20.5 / 255, Intensity: 0.5 / 255, Strobe: 200.5 / 255}
+	u := make(Universe, 6)
+	var first Universe
+	intensity := 0
+	for i := 0; i < 4; i++ {
+		if err := f.Set(u, params); err != nil {
+			t.Fatal(err)
+		}
+		if first == nil {
+			first = append(Universe(nil), u...)
+		}
+		for ch, name := range map[int]string{0: "pan", 1: "tilt", 4: "strobe"} {
+			if u[ch] != first[ch] {
+				t.Errorf("frame %d: %s is %d, was %d", i, name, u[ch], first[ch])
+			}
+		}
+		intensity += int(u[3])
+	}
+
+	if intensity != 2 {
+		t.Errorf("sum of 4 frames of intensity is %d, want 2", intensity)
+	}
+}
@@ -0,0 +1,372 @@
+package dmx
+
+import (
+	"fmt"
+	"strings"
+
+	"lyra.codes/blinken/color"
+)
+
+// UniverseSize is the number of channels in a full universe.
+const UniverseSize = 512
+
+// Role is what a channel of a fixture controls.
+type Role uint8
+
+const (
+	// Unused channels are left at their default value.
+	Unused Role = iota
+
+	Intensity
+	Red
+	Green
+	Blue
+	White
+	Amber
+	UV
+	Strobe
+	Pan
+	Tilt
+
+	// Fixed channels always hold their default value, such as a channel
+	// selecting the fixture's mode.
+	Fixed
+)
+
+var roleNames = []string{
+	Unused:    "unused",
+	Intensity: "intensity",
+	Red:       "red",
+	Green:     "green",
+	Blue:      "blue",
+	White:     "white",
+	Amber:     "amber",
+	UV:        "uv",
+	Strobe:    "strobe",
+	Pan:       "pan",
+	Tilt:      "tilt",
+	Fixed:     "fixed",
+}
+
+func (r Role) String() string {
+	if int(r) < len(roleNames) && roleNames[r] != "" {
+		return roleNames[r]
+	}
+	return fmt.Sprintf("Role(%d)", uint8(r))
+}
+
+// ParseRole parses the name of a role, as returned by String.
+func ParseRole(s string) (Role, error) {
+	for r, name := range roleNames {
+		if name != "" && strings.EqualFold(s, name) {
+			return Role(r), nil
+		}
+	}
+	return Unused, fmt.Errorf("unknown channel role %q", s)
+}
+
+// Colored reports whether the role is one of a fixture's color emitters.
+func (r Role) Colored() bool {
+	return r >= Red && r <= UV
+}
+
+// ProfileChannel is one channel of a fixture profile.
+type ProfileChannel struct {
+	Role Role
+
+	// Fine marks the low byte of a 16-bit parameter, whose high byte is
+	// the channel with the same role which isn't fine.
+	Fine bool
+
+	// Default is the value of the channel when nothing sets it, and the
+	// value of a Fixed channel.
+	Default Channel
+}
+
+// FixtureProfile describes the channels of a type of fixture.
+type FixtureProfile struct {
+	Name     string
+	Channels []ProfileChannel
+}
+
+// Footprint returns the number of channels the fixture uses.
+func (p *FixtureProfile) Footprint() int {
+	return len(p.Channels)
+}
+
+// Has reports whether the fixture has a channel for the role.
+func (p *FixtureProfile) Has(role Role) bool {
+	for _, ch := range p.Channels {
+		if ch.Role == role {
+			return true
+		}
+	}
+	return false
+}
+
+// Validate checks that every fine channel has a coarse channel.
+func (p *FixtureProfile) Validate() error {
+	for i, ch := range p.Channels {
+		if !ch.Fine {
+			continue
+		}
+		if ch.Role == Unused || ch.Role == Fixed {
+			return fmt.Errorf("profile %s: channel %d is a fine %s channel", p.Name, i+1, ch.Role)
+		}
+		if !p.hasCoarse(ch.Role) {
+			return fmt.Errorf("profile %s: fine %s channel %d has no coarse channel", p.Name, ch.Role, i+1)
+		}
+	}
+	return nil
+}
+
+func (p *FixtureProfile) hasCoarse(role Role) bool {
+	for _, ch := range p.Channels {
+		if ch.Role == role && !ch.Fine {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *FixtureProfile) hasFine(role Role) bool {
+	for _, ch := range p.Channels {
+		if ch.Role == role && ch.Fine {
+			return true
+		}
+	}
+	return false
+}
+
+// Params are levels for the roles of a fixture, from 0 to 1.
+type Params map[Role]float64
+
+// ColorParams returns the params which show a color on fixtures with the
+// profile. Fixtures without a white channel mix white from red, green and
+// blue, and fixtures without any of those show the color's brightness. An
+// intensity channel is set to full alongside the colors.
+//
+// Amber channels of fixtures with red, green or blue show the yellow in the
+// color, which red and green still show too. UV is outside a color, so UV
+// channels are left at their defaults.
+func (p *FixtureProfile) ColorParams(c color.RGBW) Params {
+	params := make(Params, 6)
+	rgb := p.Has(Red) || p.Has(Green) || p.Has(Blue)
+
+	switch {
+	case rgb && p.Has(White):
+		params[Red], params[Green], params[Blue], params[White] = c.R, c.G, c.B, c.W
+	case rgb:
+		params[Red], params[Green], params[Blue] = c.R+c.W, c.G+c.W, c.B+c.W
+	case p.Has(White):
+		params[White] = c.W + (c.R+c.G+c.B)/3
+	}
+	if rgb && p.Has(Amber) {
+		params[Amber] = amberLevel(params[Red], params[Green])
+	}
+
+	if p.Has(Intensity) {
+		if rgb || p.Has(White) {
+			params[Intensity] = 1
+		} else {
+			params[Intensity] = c.W + (c.R+c.G+c.B)/3
+		}
+	}
+
+	return params
+}
+
+// Dim returns params with the fixture's intensity scaled by level, from 0 to
+// 1: its intensity channel if it has one, otherwise its colors. Other roles,
+// like pan and tilt, are unchanged. Roles missing from params are scaled
+// from their channel's default.
+func (p *FixtureProfile) Dim(params Params, level float64) Params {
+	dimmed := make(Params, len(params)+1)
+	for role, v := range params {
+		dimmed[role] = v
+	}
+
+	intensity := p.Has(Intensity)
+	for _, ch := range p.Channels {
+		if ch.Fine || !(ch.Role == Intensity || !intensity && ch.Role.Colored()) {
+			continue
+		}
+		if _, ok := params[ch.Role]; !ok {
+			dimmed[ch.Role] = p.defaultLevel(ch.Role)
+		}
+	}
+
+	for role, v := range dimmed {
+		if role == Intensity || !intensity && role.Colored() {
+			dimmed[role] = v * level
+		}
+	}
+	return dimmed
+}
+
+// Level returns the level params set a role to, or its channel's default
+// level if they don't, from 0 to 1.
+func (p *FixtureProfile) Level(params Params, role Role) float64 {
+	if v, ok := params[role]; ok {
+		return ClampLevel(v)
+	}
+	return p.defaultLevel(role)
+}
+
+// defaultLevel returns the level of a role's default value.
+func (p *FixtureProfile) defaultLevel(role Role) float64 {
+	var coarse, fine Channel
+	var wide bool
+	for _, ch := range p.Channels {
+		switch {
+		case ch.Role != role:
+		case ch.Fine:
+			fine, wide = ch.Default, true
+		default:
+			coarse = ch.Default
+		}
+	}
+
+	if wide {
+		return wordToFloat(coarse, fine)
+	}
+	return byteToFloat(coarse)
+}
+
+// Fixture is a fixture profile patched at an address in a universe.
+type Fixture struct {
+	Profile *FixtureProfile
+
+	// Address is the fixture's first channel, from 1.
+	Address int
+
+	// Curves are applied to levels before they're written to channels.
+	Curves Curves
+
+	// Dither, if set, dithers the fixture's 8-bit intensity and color
+	// channels. Other channels, like pan or strobe, would flicker between
+	// two values, so they're never dithered.
+	Dither *Dither
+}
+
+// NewFixture patches a fixture at an address, checking that it fits in a
+// universe.
+func NewFixture(profile *FixtureProfile, address int) (*Fixture, error) {
+	if err := profile.Validate(); err != nil {
+		return nil, err
+	}
+
+	end := address + profile.Footprint() - 1
+	if address < 1 || end > UniverseSize {
+		return nil, fmt.Errorf("%s at %d uses channels %d to %d, outside 1 to %d", profile.Name, address, address, end, UniverseSize)
+	}
+
+	return &Fixture{Profile: profile, Address: address}, nil
+}
+
+// Set writes params into the fixture's channels in u. Channels whose roles
+// have no params are set to their defaults, and roles with fine channels are
+// written with 16 bits. Levels pass through the fixture's curves, and
+// intensity and colors through its dither if it has one.
+func (f *Fixture) Set(u Universe, params Params) error {
+	start := f.Address - 1
+	if start < 0 || start+f.Profile.Footprint() > len(u) {
+		return fmt.Errorf("%s at %d doesn't fit in a universe of %d channels", f.Profile.Name, f.Address, len(u))
+	}
+
+	for i, ch := range f.Profile.Channels {
+		v, ok := params[ch.Role]
+		if !ok || ch.Role == Unused || ch.Role == Fixed {
+			u[start+i] = ch.Default
+			continue
+		}
+
+		v = f.Curves.apply(ch.Role, v)
+		if f.Profile.hasFine(ch.Role) {
+			coarse, fine := floatToWord(v)
+			if ch.Fine {
+				u[start+i] = fine
+			} else {
+				u[start+i] = coarse
+			}
+		} else if ch.Role == Intensity || ch.Role.Colored() {
+			u[start+i] = f.Dither.quantize(i, v)
+		} else {
+			u[start+i] = floatToByte(v)
+		}
+	}
+
+	return nil
+}
+
+// SetColor writes a color into the fixture's channels in u, with 16 bits for
+// colors whose channels have fine channels.
+func (f *Fixture) SetColor(u Universe, c color.RGBW) error {
+	return f.Set(u, f.Profile.ColorParams(c))
+}
+
+// Word returns the channels in the universe of the first 16-bit parameter
+// with a role.
+func (f *Fixture) Word(role Role) (Word, bool) {
+	w := Word{}
+	for i, ch := range f.Profile.Channels {
+		if ch.Role != role {
+			continue
+		}
+		if ch.Fine && w.Fine == 0 {
+			w.Fine = f.Address + i
+		} else if !ch.Fine && w.Coarse == 0 {
+			w.Coarse = f.Address + i
+		}
+	}
+
+	if w.Coarse == 0 || w.Fine == 0 {
+		return Word{}, false
+	}
+	return w, true
+}
+
+// floatToWord returns the high and low bytes of a level as 16 bits.
+func floatToWord(f float64) (Channel, Channel) {
+	w := uint16(ClampLevel(f)*65535.0 + 0.5)
+	return Channel(w >> 8), Channel(w)
+}
+
+// wordToFloat returns the level of 16 bits given as high and low bytes.
+func wordToFloat(coarse, fine Channel) float64 {
+	return float64(uint16(coarse)<<8|uint16(fine)) / 65535.0
+}
+
+// ClampLevel limits a level to the range 0 to 1.
+func ClampLevel(f float64) float64 {
+	switch {
+	case f < 0:
+		return 0
+	case f > 1:
+		return 1
+	default:
+		return f
+	}
+}
+
+// Profiles of simple fixtures.
+var (
+	ProfileDimmer = &FixtureProfile{Name: "Dimmer", Channels: []ProfileChannel{{Role: Intensity}}}
+	ProfileRGB    = &FixtureProfile{Name: "RGB", Channels: []ProfileChannel{{Role: Red}, {Role: Green}, {Role: Blue}}}
+	ProfileRGBW   = &FixtureProfile{Name: "RGBW", Channels: []ProfileChannel{{Role: Red}, {Role: Green}, {Role: Blue}, {Role: White}}}
+
+	ProfileDimmer16 = &FixtureProfile{Name: "Dimmer 16-bit", Channels: []ProfileChannel{
+		{Role: Intensity}, {Role: Intensity, Fine: true},
+	}}
+	ProfileRGB16 = &FixtureProfile{Name: "RGB 16-bit", Channels: []ProfileChannel{
+		{Role: Red}, {Role: Red, Fine: true},
+		{Role: Green}, {Role: Green, Fine: true},
+		{Role: Blue}, {Role: Blue, Fine: true},
+	}}
+	ProfileRGBW16 = &FixtureProfile{Name: "RGBW 16-bit", Channels: []ProfileChannel{
+		{Role: Red}, {Role: Red, Fine: true},
+		{Role: Green}, {Role: Green, Fine: true},
+		{Role: Blue}, {Role: Blue, Fine: true},
+		{Role: White}, {Role: White, Fine: true},
+	}}
+)
@@ -0,0 +1,267 @@
+package dmx
+
+import (
+	"bytes"
+	"testing"
+
+	"lyra.codes/blinken/color"
+)
+
+var profileMover = &FixtureProfile{Name: "Mover", Channels: []ProfileChannel{
+	{Role: Pan, Default: 128},
+	{Role: Tilt, Default: 128},
+	{Role: Fixed, Default: 7},
+	{Role: Intensity},
+	{Role: Strobe, Default: 255},
+	{Role: Unused, Default: 9},
+}}
+
+var profileRGBAU = &FixtureProfile{Name: "RGBAU", Channels: []ProfileChannel{
+	{Role: Red}, {Role: Green}, {Role: Blue}, {Role: Amber}, {Role: UV, Default: 3},
+}}
+
+func TestFixtureSet(t *testing.T) {
+	tests := []struct {
+		name    string
+		profile *FixtureProfile
+		address int
+		params  Params
+		want    Universe
+	}{
+		{
+			name:    "defaults without params",
+			profile: profileMover,
+			address: 1,
+			want:    Universe{128, 128, 7, 0, 255, 9, 0, 0},
+		},
+		{
+			name:    "params override defaults",
+			profile: profileMover,
+			address: 1,
+			params:  Params{Pan: 0, Intensity: 1, Strobe: 0},
+			want:    Universe{0, 128, 7, 255, 0, 9, 0, 0},
+		},
+		{
+			name:    "fixed and unused channels ignore params",
+			profile: profileMover,
+			address: 1,
+			params:  Params{Fixed: 1, Unused: 1},
+			want:    Universe{128, 128, 7, 0, 255, 9, 0, 0},
+		},
+		{
+			name:    "at an address",
+			profile: ProfileRGB,
+			address: 4,
+			params:  Params{Red: 1, Green: 0.5},
+			want:    Universe{0, 0, 0, 255, 128, 0, 0, 0},
+		},
+		{
+			name:    "levels are clamped",
+			profile: ProfileRGB,
+			address: 1,
+			params:  Params{Red: 2, Green: -1},
+			want:    Universe{255, 0, 0, 0, 0, 0, 0, 0},
+		},
+		{
+			name:    "16-bit",
+			profile: ProfileDimmer16,
+			address: 7,
+			params:  Params{Intensity: 0.5},
+			want:    Universe{0, 0, 0, 0, 0, 0, 0x80, 0x00},
+		},
+	}
+
+	for _, tt := range tests {
+		f, err := NewFixture(tt.profile, tt.address)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+
+		u := make(Universe, 8)
+		if err := f.Set(u, tt.params); err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if !bytes.Equal(u, tt.want) {
+			t.Errorf("%s: universe is %v, want %v", tt.name, u, tt.want)
+		}
+	}
+}
+
+func TestFixtureSetOutside(t *testing.T) {
+	tests := []struct {
+		name    string
+		address int
+		size    int
+	}{
+		{"address 0", 0, UniverseSize},
+		{"negative address", -2, UniverseSize},
+		{"past the end", 511, UniverseSize},
+		{"past a short universe", 3, 4},
+	}
+
+	for _, tt := range tests {
+		f := &Fixture{Profile: ProfileRGB, Address: tt.address}
+		u := make(Universe, tt.size)
+		if err := f.Set(u, Params{Red: 1}); err == nil {
+			t.Errorf("%s: Set succeeded", tt.name)
+		}
+		if !bytes.Equal(u, make(Universe, tt.size)) {
+			t.Errorf("%s: Set changed the universe", tt.name)
+		}
+	}
+
+	for _, address := range []int{0, 511, 513} {
+		if _, err := NewFixture(ProfileRGB, address); err == nil {
+			t.Errorf("NewFixture accepted address %d", address)
+		}
+	}
+}
+
+func TestColorParams(t *testing.T) {
+	dimmerRGB := &FixtureProfile{Name: "Dimmer RGB", Channels: []ProfileChannel{
+		{Role: Intensity}, {Role: Red}, {Role: Green}, {Role: Blue},
+	}}
+
+	tests := []struct {
+		profile *FixtureProfile
+		color   color.RGBW
+		want    Params
+	}{
+		{ProfileRGBW, color.RGBW{R: 1, G: 0.5, W: 0.25}, Params{Red: 1, Green: 0.5, Blue: 0, White: 0.25}},
+		{ProfileRGB, color.RGBW{R: 0.5, W: 0.25}, Params{Red: 0.75, Green: 0.25, Blue: 0.25}},
+		{ProfileDimmer, color.RGBW{R: 0.3, G: 0.3, B: 0.3, W: 0.1}, Params{Intensity: 0.4}},
+		{dimmerRGB, color.RGBW{G: 1}, Params{Intensity: 1, Red: 0, Green: 1, Blue: 0}},
+		{profileRGBAU, color.RGBW{R: 1, G: 0.6}, Params{Red: 1, Green: 0.6, Blue: 0, Amber: 0.6}},
+		{profileRGBAU, color.RGBW{R: 1, B: 1}, Params{Red: 1, Green: 0, Blue: 1, Amber: 0}},
+	}
+
+	for _, tt := range tests {
+		got := tt.profile.ColorParams(tt.color)
+		if len(got) != len(tt.want) {
+			t.Errorf("%s %v: params are %v, want %v", tt.profile.Name, tt.color, got, tt.want)
+			continue
+		}
+		for role, v := range tt.want {
+			if g, ok := got[role]; !ok || g < v-1e-9 || g > v+1e-9 {
+				t.Errorf("%s %v: params are %v, want %v", tt.profile.Name, tt.color, got, tt.want)
+				break
+			}
+		}
+	}
+
+	// UV keeps its default.
+	f := &Fixture{Profile: profileRGBAU, Address: 1}
+	u := make(Universe, 5)
+	if err := f.SetColor(u, color.RGBW{R: 1, G: 1, B: 1}); err != nil {
+		t.Fatal(err)
+	}
+	if want := (Universe{255, 255, 255, 255, 3}); !bytes.Equal(u, want) {
+		t.Errorf("RGBAU is %v, want %v", u, want)
+	}
+}
+
+func TestDim(t *testing.T) {
+	drgb := &FixtureProfile{Name: "DRGB", Channels: []ProfileChannel{
+		{Role: Intensity, Default: 255}, {Role: Red}, {Role: Green}, {Role: Blue},
+	}}
+	rgbDefault := &FixtureProfile{Name: "RGB with defaults", Channels: []ProfileChannel{
+		{Role: Red, Default: 255}, {Role: Green}, {Role: Blue},
+	}}
+
+	tests := []struct {
+		name    string
+		profile *FixtureProfile
+		params  Params
+		level   float64
+		want    Params
+	}{
+		{
+			name:    "intensity rather than colors",
+			profile: drgb,
+			params:  Params{Intensity: 0.8, Red: 1, Green: 0.5},
+			level:   0.5,
+			want:    Params{Intensity: 0.4, Red: 1, Green: 0.5},
+		},
+		{
+			name:    "colors without intensity",
+			profile: ProfileRGBW,
+			params:  Params{Red: 1, Green: 0.5, White: 0.2},
+			level:   0.5,
+			want:    Params{Red: 0.5, Green: 0.25, Blue: 0, White: 0.1},
+		},
+		{
+			name:    "default intensity",
+			profile: drgb,
+			params:  Params{Red: 1},
+			level:   0.25,
+			want:    Params{Intensity: 0.25, Red: 1},
+		},
+		{
+			name:    "default colors",
+			profile: rgbDefault,
+			params:  Params{},
+			level:   0.5,
+			want:    Params{Red: 0.5, Green: 0, Blue: 0},
+		},
+		{
+			name:    "pan and tilt untouched",
+			profile: profileMover,
+			params:  Params{Pan: 0.3, Tilt: 0.7, Intensity: 1, Strobe: 1},
+			level:   0.5,
+			want:    Params{Pan: 0.3, Tilt: 0.7, Intensity: 0.5, Strobe: 1},
+		},
+		{
+			name:    "full",
+			profile: drgb,
+			params:  Params{Intensity: 0.6},
+			level:   1,
+			want:    Params{Intensity: 0.6},
+		},
+		{
+			name:    "blackout",
+			profile: ProfileRGB,
+			params:  Params{Red: 1, Green: 1, Blue: 1},
+			level:   0,
+			want:    Params{Red: 0, Green: 0, Blue: 0},
+		},
+	}
+
+	for _, tt := range tests {
+		got := tt.profile.Dim(tt.params, tt.level)
+		if len(got) != len(tt.want) {
+			t.Errorf("%s: params are %v, want %v", tt.name, got, tt.want)
+			continue
+		}
+		for role, v := range tt.want {
+			if g, ok := got[role]; !ok || g < v-1e-9 || g > v+1e-9 {
+				t.Errorf("%s: params are %v, want %v", tt.name, got, tt.want)
+				break
+			}
+		}
+	}
+
+	params := Params{Intensity: 1}
+	drgb.Dim(params, 0.5)
+	if params[Intensity] != 1 {
+		t.Error("Dim changed the params it was given")
+	}
+}
+
+func TestProfileLevel(t *testing.T) {
+	params := Params{Red: 0.5, Green: 2}
+	tests := []struct {
+		role Role
+		want float64
+	}{
+		{Red, 0.5},
+		{Green, 1},
+		{Blue, 0},
+		{UV, 3.0 / 255},
+	}
+
+	for _, tt := range tests {
+		if got := profileRGBAU.Level(params, tt.role); got < tt.want-1e-9 || got > tt.want+1e-9 {
+			t.Errorf("%s is %v, want %v", tt.role, got, tt.want)
+		}
+	}
+}
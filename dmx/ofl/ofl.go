@@ -0,0 +1,359 @@
+// Package ofl loads fixture definitions in the Open Fixture Library's JSON
+// format, and converts them into fixture profiles.
+//
+// See https://github.com/OpenLightingProject/open-fixture-library for the
+// format and a library of fixtures.
+package ofl
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"lyra.codes/blinken/dmx"
+)
+
+// Fixture is a fixture definition.
+type Fixture struct {
+	Name      string `json:"name"`
+	ShortName string `json:"shortName"`
+
+	AvailableChannels map[string]*Channel `json:"availableChannels"`
+	Modes             []Mode              `json:"modes"`
+
+	// RedirectTo names the fixture this one has been replaced by, as
+	// "manufacturer/fixture".
+	RedirectTo string `json:"redirectTo"`
+}
+
+// Channel is one of a fixture's available channels.
+type Channel struct {
+	FineChannelAliases []string    `json:"fineChannelAliases"`
+	DefaultValue       interface{} `json:"defaultValue"`
+	Resolution         string      `json:"dmxValueResolution"`
+	Constant           bool        `json:"constant"`
+
+	Capability   *Capability  `json:"capability"`
+	Capabilities []Capability `json:"capabilities"`
+}
+
+// Capability is what a channel does across a range of its values.
+type Capability struct {
+	Type  string `json:"type"`
+	Color string `json:"color"`
+}
+
+// Mode is one of a fixture's channel layouts.
+type Mode struct {
+	Name      string `json:"name"`
+	ShortName string `json:"shortName"`
+
+	// Channels are the names of the mode's channels. Unused channels are
+	// null, and matrix channel insertions are objects.
+	Channels []interface{} `json:"channels"`
+}
+
+// Parse parses a fixture definition.
+func Parse(data []byte) (*Fixture, error) {
+	f := &Fixture{}
+	if err := json.Unmarshal(data, f); err != nil {
+		return nil, err
+	}
+
+	if f.RedirectTo == "" && len(f.Modes) == 0 {
+		return nil, fmt.Errorf("fixture %q has no modes", f.Name)
+	}
+	return f, nil
+}
+
+// Load reads a fixture definition from a file.
+func Load(path string) (*Fixture, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	f, err := Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	return f, nil
+}
+
+// Profiles converts each of the fixture's modes into a profile.
+func (f *Fixture) Profiles() ([]*dmx.FixtureProfile, error) {
+	profiles := make([]*dmx.FixtureProfile, 0, len(f.Modes))
+	for i := range f.Modes {
+		p, err := f.profile(&f.Modes[i])
+		if err != nil {
+			return nil, err
+		}
+		profiles = append(profiles, p)
+	}
+
+	return profiles, nil
+}
+
+// Profile converts one of the fixture's modes, given by its name or short
+// name, into a profile. An empty name means the first mode.
+func (f *Fixture) Profile(mode string) (*dmx.FixtureProfile, error) {
+	for i, m := range f.Modes {
+		if mode == "" || strings.EqualFold(mode, m.Name) || strings.EqualFold(mode, m.ShortName) {
+			return f.profile(&f.Modes[i])
+		}
+	}
+
+	return nil, fmt.Errorf("fixture %q has no mode %q", f.Name, mode)
+}
+
+func (f *Fixture) profile(m *Mode) (*dmx.FixtureProfile, error) {
+	p := &dmx.FixtureProfile{
+		Name:     fmt.Sprintf("%s (%s)", f.Name, m.Name),
+		Channels: make([]dmx.ProfileChannel, 0, len(m.Channels)),
+	}
+
+	for i, entry := range m.Channels {
+		switch name := entry.(type) {
+		case nil:
+			p.Channels = append(p.Channels, dmx.ProfileChannel{Role: dmx.Unused})
+		case string:
+			ch, err := f.channel(name)
+			if err != nil {
+				return nil, fmt.Errorf("%s: channel %d: %v", p.Name, i+1, err)
+			}
+			p.Channels = append(p.Channels, ch)
+		default:
+			return nil, fmt.Errorf("%s: channel %d: matrix channels are not supported", p.Name, i+1)
+		}
+	}
+
+	if err := p.Validate(); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// channel converts the available channel with a name, or the fine channel
+// with an alias, into a profile channel.
+func (f *Fixture) channel(name string) (dmx.ProfileChannel, error) {
+	if ch, ok := f.AvailableChannels[name]; ok {
+		def, err := ch.defaultValue(0)
+		if err != nil {
+			return dmx.ProfileChannel{}, fmt.Errorf("%s: %v", name, err)
+		}
+
+		role := ch.role()
+		if ch.Constant {
+			role = dmx.Fixed
+		}
+		return dmx.ProfileChannel{Role: role, Default: def}, nil
+	}
+
+	for coarse, ch := range f.AvailableChannels {
+		for i, alias := range ch.FineChannelAliases {
+			if alias != name {
+				continue
+			}
+
+			def, err := ch.defaultValue(i + 1)
+			if err != nil {
+				return dmx.ProfileChannel{}, fmt.Errorf("%s: %v", coarse, err)
+			}
+
+			// Profiles only have one fine byte per parameter.
+			role := ch.role()
+			if i > 0 || role == dmx.Unused || role == dmx.Fixed || ch.Constant {
+				return dmx.ProfileChannel{Role: dmx.Unused, Default: def}, nil
+			}
+			return dmx.ProfileChannel{Role: role, Fine: true, Default: def}, nil
+		}
+	}
+
+	return dmx.ProfileChannel{}, fmt.Errorf("no channel named %q", name)
+}
+
+// role returns the role of the channel's first capability which blinken
+// can control.
+func (ch *Channel) role() dmx.Role {
+	caps := ch.Capabilities
+	if ch.Capability != nil {
+		caps = []Capability{*ch.Capability}
+	}
+
+	for _, c := range caps {
+		if role := c.role(); role != dmx.Unused {
+			return role
+		}
+	}
+	return dmx.Unused
+}
+
+func (c Capability) role() dmx.Role {
+	switch c.Type {
+	case "Intensity":
+		return dmx.Intensity
+	case "ShutterStrobe", "StrobeSpeed":
+		return dmx.Strobe
+	case "Pan":
+		return dmx.Pan
+	case "Tilt":
+		return dmx.Tilt
+	case "ColorIntensity":
+		switch c.Color {
+		case "Red":
+			return dmx.Red
+		case "Green":
+			return dmx.Green
+		case "Blue":
+			return dmx.Blue
+		case "White", "Warm White", "Cold White":
+			return dmx.White
+		case "Amber":
+			return dmx.Amber
+		case "UV":
+			return dmx.UV
+		}
+	}
+	return dmx.Unused
+}
+
+// defaultValue returns one byte of the channel's default value, counting
+// from the most significant.
+func (ch *Channel) defaultValue(byteIndex int) (dmx.Channel, error) {
+	bytes := 1 + len(ch.FineChannelAliases)
+	resolution := bytes
+	switch ch.Resolution {
+	case "":
+	case "8bit":
+		resolution = 1
+	case "16bit":
+		resolution = 2
+	case "24bit":
+		resolution = 3
+	default:
+		return 0, fmt.Errorf("unknown resolution %q", ch.Resolution)
+	}
+
+	max := uint64(1)<<(8*uint(resolution)) - 1
+	var v uint64
+
+	switch d := ch.DefaultValue.(type) {
+	case nil:
+	case float64:
+		if d < 0 || d > float64(max) {
+			return 0, fmt.Errorf("default value %v is out of range", d)
+		}
+		v = uint64(d)
+	case string:
+		pct, err := strconv.ParseFloat(strings.TrimSuffix(d, "%"), 64)
+		if err != nil || !strings.HasSuffix(d, "%") || pct < 0 || pct > 100 {
+			return 0, fmt.Errorf("invalid default value %q", d)
+		}
+		v = uint64(pct/100*float64(max) + 0.5)
+	default:
+		return 0, fmt.Errorf("invalid default value %v", d)
+	}
+
+	// Scale a value given in a different resolution to the channel's own.
+	if resolution < bytes {
+		v <<= 8 * uint(bytes-resolution)
+	}
+
+	return dmx.Channel(v >> (8 * uint(bytes-1-byteIndex))), nil
+}
+
+// Library is a set of fixture definitions, keyed by "manufacturer/fixture".
+type Library map[string]*Fixture
+
+// LoadError lists the files LoadDir couldn't load.
+type LoadError struct {
+	Errors []error
+}
+
+func (e *LoadError) Error() string {
+	msgs := make([]string, len(e.Errors))
+	for i, err := range e.Errors {
+		msgs[i] = err.Error()
+	}
+	return fmt.Sprintf("%d fixture definitions failed to load: %s", len(e.Errors), strings.Join(msgs, "; "))
+}
+
+// LoadDir loads every fixture definition in a directory laid out like the
+// Open Fixture Library's fixtures directory, with one subdirectory for each
+// manufacturer.
+//
+// Files which can't be loaded are skipped, so one bad definition doesn't
+// stop the rest being imported. LoadDir then returns the fixtures it loaded
+// along with a *LoadError listing the files it skipped.
+func LoadDir(dir string) (Library, error) {
+	lib := make(Library)
+	var skipped []error
+
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			if path == dir {
+				return err
+			}
+			skipped = append(skipped, err)
+			return nil
+		}
+		if info.IsDir() || filepath.Ext(path) != ".json" {
+			return nil
+		}
+
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		key := filepath.ToSlash(strings.TrimSuffix(rel, ".json"))
+		if !strings.Contains(key, "/") {
+			// Files at the top level, such as manufacturers.json,
+			// aren't fixtures.
+			return nil
+		}
+
+		f, err := Load(path)
+		if err != nil {
+			skipped = append(skipped, err)
+			return nil
+		}
+		lib[key] = f
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if len(skipped) > 0 {
+		return lib, &LoadError{Errors: skipped}
+	}
+	return lib, nil
+}
+
+// Fixture returns the fixture with a key, following redirects.
+func (l Library) Fixture(key string) (*Fixture, error) {
+	for i := 0; i < len(l); i++ {
+		f, ok := l[key]
+		if !ok {
+			return nil, fmt.Errorf("no fixture %q", key)
+		}
+		if f.RedirectTo == "" {
+			return f, nil
+		}
+		key = f.RedirectTo
+	}
+
+	return nil, fmt.Errorf("fixture %q redirects in a loop", key)
+}
+
+// Profile returns the profile for a mode of the fixture with a key.
+func (l Library) Profile(key, mode string) (*dmx.FixtureProfile, error) {
+	f, err := l.Fixture(key)
+	if err != nil {
+		return nil, err
+	}
+	return f.Profile(mode)
+}
@@ -0,0 +1,149 @@
+package ofl
+
+import (
+	"errors"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"lyra.codes/blinken/dmx"
+)
+
+func loadTestdata(t *testing.T) Library {
+	t.Helper()
+
+	lib, err := LoadDir(filepath.Join("testdata", "fixtures"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return lib
+}
+
+func TestLoadDir(t *testing.T) {
+	lib := loadTestdata(t)
+
+	var keys []string
+	for key := range lib {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	want := []string{"blinken/old-spot", "blinken/spot", "generic/desk-channel", "generic/drgb-fader", "generic/rgb-fader"}
+	if !reflect.DeepEqual(keys, want) {
+		t.Errorf("loaded %v, want %v", keys, want)
+	}
+}
+
+func TestLoadDirSkipsBadFiles(t *testing.T) {
+	lib, err := LoadDir(filepath.Join("testdata", "broken"))
+
+	var lerr *LoadError
+	if !errors.As(err, &lerr) {
+		t.Fatalf("error is %v, want a LoadError", err)
+	}
+	if len(lerr.Errors) != 2 {
+		t.Errorf("%d files failed, want 2: %v", len(lerr.Errors), err)
+	}
+	for _, name := range []string{"no-modes.json", "truncated.json"} {
+		if !strings.Contains(err.Error(), name) {
+			t.Errorf("error doesn't mention %s: %v", name, err)
+		}
+	}
+
+	if _, ok := lib["generic/rgb-fader"]; !ok || len(lib) != 1 {
+		t.Errorf("loaded %v, want only generic/rgb-fader", lib)
+	}
+}
+
+func TestLoadDirMissing(t *testing.T) {
+	if _, err := LoadDir(filepath.Join("testdata", "missing")); err == nil {
+		t.Error("LoadDir of a missing directory succeeded")
+	}
+}
+
+func TestProfiles(t *testing.T) {
+	lib := loadTestdata(t)
+
+	tests := []struct {
+		key, mode string
+		want      []dmx.ProfileChannel
+	}{
+		{"generic/desk-channel", "", []dmx.ProfileChannel{{Role: dmx.Intensity}}},
+		{"generic/rgb-fader", "3ch", []dmx.ProfileChannel{{Role: dmx.Red}, {Role: dmx.Green}, {Role: dmx.Blue}}},
+		{"generic/drgb-fader", "4-channel", []dmx.ProfileChannel{
+			{Role: dmx.Intensity, Default: 255}, {Role: dmx.Red}, {Role: dmx.Green}, {Role: dmx.Blue},
+		}},
+		{"blinken/spot", "Standard", []dmx.ProfileChannel{
+			{Role: dmx.Pan, Default: 128},
+			{Role: dmx.Tilt, Default: 128},
+			{Role: dmx.Strobe, Default: 255},
+			{Role: dmx.Intensity},
+			{Role: dmx.Amber},
+			{Role: dmx.Fixed, Default: 7},
+		}},
+		{"blinken/old-spot", "ext", []dmx.ProfileChannel{
+			{Role: dmx.Pan, Default: 128},
+			{Role: dmx.Pan, Fine: true},
+			{Role: dmx.Tilt, Default: 128},
+			{Role: dmx.Tilt, Fine: true},
+			{Role: dmx.Strobe, Default: 255},
+			{Role: dmx.Intensity},
+			{Role: dmx.Intensity, Fine: true},
+			{Role: dmx.Unused},
+			{Role: dmx.Amber},
+			{Role: dmx.Fixed, Default: 7},
+		}},
+	}
+
+	for _, tt := range tests {
+		p, err := lib.Profile(tt.key, tt.mode)
+		if err != nil {
+			t.Errorf("%s %s: %v", tt.key, tt.mode, err)
+			continue
+		}
+		if !reflect.DeepEqual(p.Channels, tt.want) {
+			t.Errorf("%s %s: channels are %v, want %v", tt.key, tt.mode, p.Channels, tt.want)
+		}
+	}
+
+	if _, err := lib.Profile("generic/rgb-fader", "7ch"); err == nil {
+		t.Error("Profile found a missing mode")
+	}
+	if _, err := lib.Profile("generic/missing", ""); err == nil {
+		t.Error("Profile found a missing fixture")
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		json string
+	}{
+		{"matrix channel", `{"name": "M", "availableChannels": {}, "modes": [{"name": "m", "channels": [{"insert": "matrixChannels"}]}]}`},
+		{"missing channel", `{"name": "M", "availableChannels": {}, "modes": [{"name": "m", "channels": ["Red"]}]}`},
+		{"default out of range", `{"name": "M", "availableChannels": {"D": {"defaultValue": 256}}, "modes": [{"name": "m", "channels": ["D"]}]}`},
+		{"bad percentage", `{"name": "M", "availableChannels": {"D": {"defaultValue": "half"}}, "modes": [{"name": "m", "channels": ["D"]}]}`},
+	}
+
+	for _, tt := range tests {
+		f, err := Parse([]byte(tt.json))
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if _, err := f.Profiles(); err == nil {
+			t.Errorf("%s: Profiles succeeded", tt.name)
+		}
+	}
+}
+
+func TestRedirectLoop(t *testing.T) {
+	lib := Library{
+		"a/one": {RedirectTo: "a/two"},
+		"a/two": {RedirectTo: "a/one"},
+	}
+	if _, err := lib.Fixture("a/one"); err == nil {
+		t.Error("Fixture followed a redirect loop")
+	}
+}
@@ -0,0 +1,243 @@
+package dmx
+
+import (
+	"fmt"
+	"strings"
+
+	"lyra.codes/blinken/color"
+)
+
+// PixelLayout is the order of the color channels of a pixel.
+type PixelLayout struct {
+	Name  string
+	Order []Role
+
+	// Wide pixels have 16 bits for each color, in two channels with the
+	// high byte first.
+	Wide bool
+}
+
+// Pixel layouts. Layouts with two white channels set both to the color's
+// white. Amber channels show the yellow in a color, the lesser of its red
+// and green, and UV channels are left off.
+var (
+	LayoutRGB   = PixelLayout{Name: "RGB", Order: []Role{Red, Green, Blue}}
+	LayoutRGBW  = PixelLayout{Name: "RGBW", Order: []Role{Red, Green, Blue, White}}
+	LayoutRGBWW = PixelLayout{Name: "RGBWW", Order: []Role{Red, Green, Blue, White, White}}
+	LayoutRGBA  = PixelLayout{Name: "RGBA", Order: []Role{Red, Green, Blue, Amber}}
+	LayoutGRB   = PixelLayout{Name: "GRB", Order: []Role{Green, Red, Blue}}
+	LayoutGRBW  = PixelLayout{Name: "GRBW", Order: []Role{Green, Red, Blue, White}}
+	LayoutWRGB  = PixelLayout{Name: "WRGB", Order: []Role{White, Red, Green, Blue}}
+	LayoutBGR   = PixelLayout{Name: "BGR", Order: []Role{Blue, Green, Red}}
+)
+
+// layoutRoles are the letters of each role in a layout's name.
+var layoutRoles = map[byte]Role{
+	'R': Red,
+	'G': Green,
+	'B': Blue,
+	'W': White,
+	'A': Amber,
+	'U': UV,
+}
+
+// ParsePixelLayout parses a layout written as its channel order, such as
+// "GRBW", with R, G, B, W, A (amber) and U (UV). A "16" suffix, as in
+// "RGB16", makes the layout wide.
+func ParsePixelLayout(s string) (PixelLayout, error) {
+	name := strings.ToUpper(s)
+	l := PixelLayout{Name: name}
+
+	order := name
+	if strings.HasSuffix(order, "16") {
+		order = strings.TrimSuffix(order, "16")
+		l.Wide = true
+	}
+	if order == "" {
+		return PixelLayout{}, fmt.Errorf("pixel layout %q has no channels", s)
+	}
+
+	for i := 0; i < len(order); i++ {
+		role, ok := layoutRoles[order[i]]
+		if !ok {
+			return PixelLayout{}, fmt.Errorf("pixel layout %q has unknown channel %q", s, order[i])
+		}
+		if role != White && l.Has(role) {
+			return PixelLayout{}, fmt.Errorf("pixel layout %q has %s twice", s, role)
+		}
+		l.Order = append(l.Order, role)
+	}
+
+	return l, nil
+}
+
+func (l PixelLayout) String() string {
+	return l.Name
+}
+
+// Footprint returns the number of channels in a pixel.
+func (l PixelLayout) Footprint() int {
+	if l.Wide {
+		return len(l.Order) * 2
+	}
+	return len(l.Order)
+}
+
+// Has reports whether the layout has a channel for the role.
+func (l PixelLayout) Has(role Role) bool {
+	for _, r := range l.Order {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
+
+// Mix returns a color as the layout's pixels show it. Pixels without a white
+// channel mix white from red, green and blue, so the color's white is added
+// to those.
+func (l PixelLayout) Mix(c color.RGBW) color.RGBW {
+	if l.Has(White) {
+		return c
+	}
+	return color.RGBW{R: c.R + c.W, G: c.G + c.W, B: c.B + c.W}
+}
+
+// Levels returns the level of each of the layout's roles in a pixel showing
+// a color, from 0 to 1.
+func (l PixelLayout) Levels(c color.RGBW) Params {
+	c = l.Mix(c)
+	params := make(Params, len(l.Order))
+	for _, role := range l.Order {
+		params[role] = ClampLevel(colorLevel(c, role))
+	}
+	return params
+}
+
+// Set writes a color into the channels of a pixel. Pixels without a white
+// channel mix white from red, green and blue.
+func (l PixelLayout) Set(pixel []Channel, c color.RGBW) {
+	l.SetCurves(pixel, c, nil)
+}
+
+// SetCurves writes a color into the channels of a pixel, passing each level
+// through the curve for its role.
+func (l PixelLayout) SetCurves(pixel []Channel, c color.RGBW, curves Curves) {
+	l.set(pixel, c, curves, nil, 0)
+}
+
+// set writes a pixel whose first channel has index base in a dither.
+func (l PixelLayout) set(pixel []Channel, c color.RGBW, curves Curves, dither *Dither, base int) {
+	c = l.Mix(c)
+	for i, role := range l.Order {
+		v := curves.apply(role, colorLevel(c, role))
+		if l.Wide {
+			pixel[2*i], pixel[2*i+1] = floatToWord(v)
+		} else {
+			pixel[i] = dither.quantize(base+i, v)
+		}
+	}
+}
+
+// At reads the color of a pixel from its channels.
+func (l PixelLayout) At(pixel []Channel) color.RGBW {
+	var c color.RGBW
+	for i, role := range l.Order {
+		var v float64
+		if l.Wide {
+			v = wordToFloat(pixel[2*i], pixel[2*i+1])
+		} else {
+			v = byteToFloat(pixel[i])
+		}
+
+		switch role {
+		case Red:
+			c.R = v
+		case Green:
+			c.G = v
+		case Blue:
+			c.B = v
+		case White:
+			c.W = v
+		}
+	}
+	return c
+}
+
+// Inspect formats the channels of a pixel.
+func (l PixelLayout) Inspect(pixel []Channel) string {
+	values := make([]string, len(l.Order))
+	for i := range l.Order {
+		if l.Wide {
+			values[i] = fmt.Sprintf("%05d", uint16(pixel[2*i])<<8|uint16(pixel[2*i+1]))
+		} else {
+			values[i] = fmt.Sprintf("%03d", pixel[i])
+		}
+	}
+	return "(" + strings.Join(values, ", ") + ")"
+}
+
+// colorLevel returns the level of a color for a role, or zero if the color
+// has no level for it.
+func colorLevel(c color.RGBW, role Role) float64 {
+	switch role {
+	case Red:
+		return c.R
+	case Green:
+		return c.G
+	case Blue:
+		return c.B
+	case White:
+		return c.W
+	case Amber:
+		return amberLevel(c.R, c.G)
+	default:
+		return 0
+	}
+}
+
+// Pixels is one or more pixels with the same layout as DMX channels.
+type Pixels struct {
+	Layout   PixelLayout
+	Channels []Channel
+
+	// Curves are applied to colors set on the pixels.
+	Curves Curves
+
+	// Dither, if set, dithers pixels which aren't wide.
+	Dither *Dither
+}
+
+func (p Pixels) Len() int {
+	return len(p.Channels) / p.Layout.Footprint()
+}
+
+func (p Pixels) At(index int) color.RGBW {
+	return p.Layout.At(p.pixel(index))
+}
+
+func (p Pixels) Set(index int, c color.RGBW) {
+	p.Layout.set(p.pixel(index), c, p.Curves, p.Dither, index*p.Layout.Footprint())
+}
+
+func (p Pixels) Spread(startIndex int, colors []color.RGBW) {
+	for i, c := range colors {
+		p.Set(startIndex+i, c)
+	}
+}
+
+func (p Pixels) Inspect() []string {
+	l := p.Len()
+	out := make([]string, 0, l)
+
+	for i := 0; i < l; i++ {
+		out = append(out, p.Layout.Inspect(p.pixel(i)))
+	}
+
+	return out
+}
+
+func (p Pixels) pixel(index int) []Channel {
+	n := p.Layout.Footprint()
+	return p.Channels[index*n : (index+1)*n]
+}
@@ -0,0 +1,131 @@
+package dmx
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+
+	"lyra.codes/blinken/color"
+)
+
+func TestParsePixelLayout(t *testing.T) {
+	tests := []struct {
+		s    string
+		want PixelLayout
+	}{
+		{"RGB", LayoutRGB},
+		{"grbw", LayoutGRBW},
+		{"RGBWW", LayoutRGBWW},
+		{"RGBA", LayoutRGBA},
+		{"WRGB", LayoutWRGB},
+		{"rgbau", PixelLayout{Name: "RGBAU", Order: []Role{Red, Green, Blue, Amber, UV}}},
+		{"RGB16", PixelLayout{Name: "RGB16", Order: []Role{Red, Green, Blue}, Wide: true}},
+		{"W", PixelLayout{Name: "W", Order: []Role{White}}},
+	}
+
+	for _, tt := range tests {
+		got, err := ParsePixelLayout(tt.s)
+		if err != nil {
+			t.Errorf("%s: %v", tt.s, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: layout is %+v, want %+v", tt.s, got, tt.want)
+		}
+	}
+
+	for _, s := range []string{"", "16", "RGBX", "RRGB", "RGB8"} {
+		if _, err := ParsePixelLayout(s); err == nil {
+			t.Errorf("%q: ParsePixelLayout succeeded", s)
+		}
+	}
+}
+
+func TestPixelLayoutSet(t *testing.T) {
+	tests := []struct {
+		layout PixelLayout
+		color  color.RGBW
+		want   []Channel
+	}{
+		{LayoutRGB, color.RGBW{R: 1, G: 0.5}, []Channel{255, 128, 0}},
+		{LayoutRGB, color.RGBW{R: 0.5, W: 0.5}, []Channel{255, 128, 128}},
+		{LayoutGRB, color.RGBW{R: 1, G: 0.5}, []Channel{128, 255, 0}},
+		{LayoutBGR, color.RGBW{B: 1}, []Channel{255, 0, 0}},
+		{LayoutRGBW, color.RGBW{R: 1, W: 0.5}, []Channel{255, 0, 0, 128}},
+		{LayoutGRBW, color.RGBW{G: 1, W: 1}, []Channel{255, 0, 0, 255}},
+		{LayoutWRGB, color.RGBW{B: 1, W: 0.5}, []Channel{128, 0, 0, 255}},
+		{LayoutRGBWW, color.RGBW{W: 1}, []Channel{0, 0, 0, 255, 255}},
+		{LayoutRGBA, color.RGBW{R: 1, G: 0.5}, []Channel{255, 128, 0, 128}},
+		{LayoutRGBA, color.RGBW{R: 1, B: 1}, []Channel{255, 0, 255, 0}},
+		{LayoutRGBA, color.RGBW{W: 1}, []Channel{255, 255, 255, 255}},
+		{PixelLayout{Name: "RGB16", Order: []Role{Red, Green, Blue}, Wide: true}, color.RGBW{R: 1, G: 0.5}, []Channel{0xff, 0xff, 0x80, 0x00, 0, 0}},
+	}
+
+	for _, tt := range tests {
+		pixel := make([]Channel, tt.layout.Footprint())
+		tt.layout.Set(pixel, tt.color)
+		if !bytes.Equal(pixel, tt.want) {
+			t.Errorf("%s %v: pixel is %v, want %v", tt.layout, tt.color, pixel, tt.want)
+		}
+	}
+}
+
+func TestPixelLayoutAt(t *testing.T) {
+	tests := []struct {
+		layout PixelLayout
+		pixel  []Channel
+		want   color.RGBW
+	}{
+		{LayoutGRBW, []Channel{255, 0, 0, 255}, color.RGBW{G: 1, W: 1}},
+		{LayoutRGBA, []Channel{0, 0, 255, 255}, color.RGBW{B: 1}},
+		{PixelLayout{Name: "W16", Order: []Role{White}, Wide: true}, []Channel{0xff, 0xff}, color.RGBW{W: 1}},
+	}
+
+	for _, tt := range tests {
+		if got := tt.layout.At(tt.pixel); got != tt.want {
+			t.Errorf("%s %v: color is %v, want %v", tt.layout, tt.pixel, got, tt.want)
+		}
+	}
+}
+
+func TestPixelsSpread(t *testing.T) {
+	p := Pixels{Layout: LayoutGRB, Channels: make([]Channel, 9)}
+	if p.Len() != 3 {
+		t.Fatalf("%d pixels, want 3", p.Len())
+	}
+
+	p.Spread(1, []color.RGBW{{R: 1}, {G: 1}})
+	if want := []Channel{0, 0, 0, 0, 255, 0, 255, 0, 0}; !bytes.Equal(p.Channels, want) {
+		t.Errorf("channels are %v, want %v", p.Channels, want)
+	}
+	if got := p.At(2); got != (color.RGBW{G: 1}) {
+		t.Errorf("pixel 2 is %v", got)
+	}
+}
+
+func TestPixelLayoutLevels(t *testing.T) {
+	tests := []struct {
+		layout PixelLayout
+		color  color.RGBW
+		want   Params
+	}{
+		{LayoutRGBW, color.RGBW{R: 1, W: 0.5}, Params{Red: 1, Green: 0, Blue: 0, White: 0.5}},
+		{LayoutRGB, color.RGBW{R: 0.5, W: 0.25}, Params{Red: 0.75, Green: 0.25, Blue: 0.25}},
+		{LayoutRGB, color.RGBW{R: 1, W: 1}, Params{Red: 1, Green: 1, Blue: 1}},
+		{LayoutRGBA, color.RGBW{R: 1, G: 0.5}, Params{Red: 1, Green: 0.5, Blue: 0, Amber: 0.5}},
+	}
+
+	for _, tt := range tests {
+		got := tt.layout.Levels(tt.color)
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s %v: levels are %v, want %v", tt.layout, tt.color, got, tt.want)
+		}
+	}
+
+	if got := LayoutRGBW.Mix(color.RGBW{R: 0.5, W: 0.5}); got != (color.RGBW{R: 0.5, W: 0.5}) {
+		t.Errorf("RGBW mixed %v", got)
+	}
+	if got := LayoutGRB.Mix(color.RGBW{R: 0.5, W: 0.5}); got != (color.RGBW{R: 1, G: 0.5, B: 0.5}) {
+		t.Errorf("GRB mixed %v", got)
+	}
+}
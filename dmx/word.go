@@ -0,0 +1,68 @@
+package dmx
+
+import "fmt"
+
+// Word is a 16-bit parameter held in two channels of a universe, the
+// coarse channel with the high byte and the fine channel with the low byte.
+// The channels need not be adjacent. Channels are numbered from 1, and
+// reading or writing a word fails unless both are in the universe.
+type Word struct {
+	Coarse int
+	Fine   int
+}
+
+// AdjacentWord returns the word with its coarse channel at ch, followed by
+// its fine channel.
+func AdjacentWord(ch int) Word {
+	return Word{Coarse: ch, Fine: ch + 1}
+}
+
+func (w Word) String() string {
+	return fmt.Sprintf("%d/%d", w.Coarse, w.Fine)
+}
+
+// check returns an error unless both of the word's channels are distinct
+// channels of u.
+func (w Word) check(u Universe) error {
+	if w.Coarse < 1 || w.Coarse > len(u) || w.Fine < 1 || w.Fine > len(u) {
+		return fmt.Errorf("word %s is outside a universe of %d channels", w, len(u))
+	}
+	if w.Coarse == w.Fine {
+		return fmt.Errorf("word %s uses one channel for both bytes", w)
+	}
+	return nil
+}
+
+// Value returns the word's 16-bit value in u.
+func (w Word) Value(u Universe) (uint16, error) {
+	if err := w.check(u); err != nil {
+		return 0, err
+	}
+	return uint16(u[w.Coarse-1])<<8 | uint16(u[w.Fine-1]), nil
+}
+
+// SetValue writes a 16-bit value into the word's channels in u.
+func (w Word) SetValue(u Universe, v uint16) error {
+	if err := w.check(u); err != nil {
+		return err
+	}
+	u[w.Coarse-1], u[w.Fine-1] = Channel(v>>8), Channel(v)
+	return nil
+}
+
+// At returns the word's level in u, from 0 to 1.
+func (w Word) At(u Universe) (float64, error) {
+	if err := w.check(u); err != nil {
+		return 0, err
+	}
+	return wordToFloat(u[w.Coarse-1], u[w.Fine-1]), nil
+}
+
+// Set writes a level from 0 to 1 into the word's channels in u.
+func (w Word) Set(u Universe, level float64) error {
+	if err := w.check(u); err != nil {
+		return err
+	}
+	u[w.Coarse-1], u[w.Fine-1] = floatToWord(level)
+	return nil
+}
@@ -0,0 +1,143 @@
+package dmx
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestWord(t *testing.T) {
+	tests := []struct {
+		name  string
+		word  Word
+		value uint16
+		want  Universe
+	}{
+		{"adjacent", AdjacentWord(1), 0x1234, Universe{0x12, 0x34, 0, 0}},
+		{"adjacent at the end", AdjacentWord(3), 0xff00, Universe{0, 0, 0xff, 0x00}},
+		{"fine first", Word{Coarse: 2, Fine: 1}, 0x1234, Universe{0x34, 0x12, 0, 0}},
+		{"not adjacent", Word{Coarse: 1, Fine: 4}, 0xabcd, Universe{0xab, 0, 0, 0xcd}},
+	}
+
+	for _, tt := range tests {
+		u := make(Universe, 4)
+		if err := tt.word.SetValue(u, tt.value); err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if !bytes.Equal(u, tt.want) {
+			t.Errorf("%s: universe is %v, want %v", tt.name, u, tt.want)
+		}
+
+		if v, err := tt.word.Value(u); err != nil || v != tt.value {
+			t.Errorf("%s: value is 0x%04x, %v, want 0x%04x", tt.name, v, err, tt.value)
+		}
+		if level, err := tt.word.At(u); err != nil || level != float64(tt.value)/65535 {
+			t.Errorf("%s: level is %v, %v", tt.name, level, err)
+		}
+	}
+}
+
+func TestWordLevel(t *testing.T) {
+	tests := []struct {
+		level float64
+		value uint16
+	}{
+		{0, 0},
+		{1, 0xffff},
+		{0.5, 0x8000},
+		{1.0 / 65535, 1},
+		{-1, 0},
+		{2, 0xffff},
+	}
+
+	w := Word{Coarse: 3, Fine: 1}
+	for _, tt := range tests {
+		u := make(Universe, 3)
+		if err := w.Set(u, tt.level); err != nil {
+			t.Fatal(err)
+		}
+		if v, _ := w.Value(u); v != tt.value {
+			t.Errorf("level %v is 0x%04x, want 0x%04x", tt.level, v, tt.value)
+		}
+	}
+}
+
+func TestWordOutside(t *testing.T) {
+	u := make(Universe, UniverseSize)
+	for _, w := range []Word{{}, AdjacentWord(512), AdjacentWord(0), {Coarse: 513, Fine: 1}, {Coarse: 5, Fine: 5}} {
+		if err := w.Set(u, 1); err == nil {
+			t.Errorf("%s: Set succeeded", w)
+		}
+		if err := w.SetValue(u, 1); err == nil {
+			t.Errorf("%s: SetValue succeeded", w)
+		}
+		if _, err := w.Value(u); err == nil {
+			t.Errorf("%s: Value succeeded", w)
+		}
+		if _, err := w.At(u); err == nil {
+			t.Errorf("%s: At succeeded", w)
+		}
+	}
+	if !bytes.Equal(u, make(Universe, UniverseSize)) {
+		t.Error("a word outside the universe changed it")
+	}
+}
+
+func TestFixtureWord(t *testing.T) {
+	tests := []struct {
+		profile *FixtureProfile
+		role    Role
+		want    Word
+		ok      bool
+	}{
+		{ProfileDimmer16, Intensity, Word{Coarse: 10, Fine: 11}, true},
+		{ProfileRGB16, Green, Word{Coarse: 12, Fine: 13}, true},
+		{ProfileRGBW16, White, Word{Coarse: 16, Fine: 17}, true},
+		{ProfileRGB16, White, Word{}, false},
+		{ProfileRGB, Red, Word{}, false},
+		{&FixtureProfile{Name: "Split", Channels: []ProfileChannel{
+			{Role: Pan}, {Role: Tilt}, {Role: Pan, Fine: true}, {Role: Tilt, Fine: true},
+		}}, Tilt, Word{Coarse: 11, Fine: 13}, true},
+	}
+
+	for _, tt := range tests {
+		f := &Fixture{Profile: tt.profile, Address: 10}
+		w, ok := f.Word(tt.role)
+		if ok != tt.ok || w != tt.want {
+			t.Errorf("%s %s: word is %s, %v, want %s, %v", tt.profile.Name, tt.role, w, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestFixtureSetWide(t *testing.T) {
+	tests := []struct {
+		profile *FixtureProfile
+		params  Params
+		want    Universe
+	}{
+		{ProfileDimmer16, Params{Intensity: 1.0 / 65535}, Universe{0x00, 0x01}},
+		{ProfileRGB16, Params{Red: 1, Green: 0.5, Blue: 0.25}, Universe{0xff, 0xff, 0x80, 0x00, 0x40, 0x00}},
+		{ProfileRGBW16, Params{White: 0.75}, Universe{0, 0, 0, 0, 0, 0, 0xbf, 0xff}},
+	}
+
+	for _, tt := range tests {
+		f, err := NewFixture(tt.profile, 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		u := make(Universe, len(tt.want))
+		if err := f.Set(u, tt.params); err != nil {
+			t.Fatalf("%s: %v", tt.profile.Name, err)
+		}
+		if !bytes.Equal(u, tt.want) {
+			t.Errorf("%s: universe is %v, want %v", tt.profile.Name, u, tt.want)
+		}
+
+		for role, level := range tt.params {
+			w, _ := f.Word(role)
+			if got, err := w.At(u); err != nil || got < level-1.0/65535 || got > level+1.0/65535 {
+				t.Errorf("%s: %s reads back as %v, %v, want %v", tt.profile.Name, role, got, err, level)
+			}
+		}
+	}
+}
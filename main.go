@@ -2,6 +2,7 @@ package main // import "lyra.codes/blinken"
 
 import (
 	"context"
+	"flag"
 	"fmt"
 	"os"
 	"os/signal"
@@ -9,6 +10,7 @@ import (
 	"time"
 
 	"lyra.codes/blinken/artnet"
+	"lyra.codes/blinken/clock"
 	"lyra.codes/blinken/color"
 	"lyra.codes/blinken/dmx"
 )
@@ -29,6 +31,17 @@ func main() {
 		os.Exit(2)
 	}()
 
+	if len(os.Args) > 1 && os.Args[1] == "bridge" {
+		if err := runBridge(ctx, os.Args[2:]); err != nil {
+			fmt.Printf("Bridge failed: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	timecode := flag.Bool("timecode", false, "chase received ArtTimeCode instead of wall time")
+	flag.Parse()
+
 	transport, err := artnet.Listen(ctx, nil)
 	if err != nil {
 		fmt.Printf("Failed to listen: %v\n", err)
@@ -52,12 +65,19 @@ func main() {
 	port := node.Ports[0]
 	fmt.Printf("Rendering to port %s (%08b)\n", port.Address, port.Type)
 
+	var clk clock.Clock = clock.NewWall()
+	if *timecode {
+		tc := artnet.NewTimeCodeClock(transport, 0)
+		defer tc.Close()
+		clk = tc
+	}
+
 	var q uint8
-	s := 0
 	uni := make(dmx.Universe, 512)
 	colors := make([]color.RGBW, 50)
 
 	for {
+		s := int(clk.Now()/time.Second) % len(colors)
 		for i := 0; i < len(colors); i++ {
 			h := float64((int(float64(i+s) / float64(len(colors)) * 360)) % 360)
 			fmt.Printf("%.0f ", h)
@@ -72,7 +92,6 @@ func main() {
 			return
 		}
 
-		s = (s + 1) % len(colors)
 		q++
 		time.Sleep(time.Second)
 		fmt.Println()
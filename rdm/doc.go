@@ -0,0 +1,2 @@
+// Package rdm implements ANSI E1.20 Remote Device Management messages.
+package rdm
@@ -0,0 +1,202 @@
+package rdm
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+
+	"lyra.codes/blinken/artnet/wire"
+)
+
+const (
+	// StartCode is the DMX start code of RDM packets.
+	StartCode uint8 = 0xCC
+	// SubStartCode is the sub-start code of RDM messages.
+	SubStartCode uint8 = 0x01
+
+	// headerLength is the length of a message before its parameter data.
+	headerLength = 24
+
+	// MaxParameterData is the largest parameter data a message can carry.
+	MaxParameterData = 231
+)
+
+// CommandClass is the kind of an RDM message.
+type CommandClass uint8
+
+const (
+	DiscoveryCommand         CommandClass = 0x10
+	DiscoveryCommandResponse CommandClass = 0x11
+	GetCommand               CommandClass = 0x20
+	GetCommandResponse       CommandClass = 0x21
+	SetCommand               CommandClass = 0x30
+	SetCommandResponse       CommandClass = 0x31
+)
+
+// Response returns the command class of a response to c.
+func (c CommandClass) Response() CommandClass {
+	return c | 0x01
+}
+
+// IsResponse reports whether c is the command class of a response.
+func (c CommandClass) IsResponse() bool {
+	return c&0x01 != 0
+}
+
+// ResponseType is the outcome reported by a response message.
+type ResponseType uint8
+
+const (
+	ResponseAck         ResponseType = 0x00
+	ResponseAckTimer    ResponseType = 0x01
+	ResponseNackReason  ResponseType = 0x02
+	ResponseAckOverflow ResponseType = 0x03
+)
+
+// NackReason is the reason a device gives for a ResponseNackReason.
+type NackReason uint16
+
+const (
+	NackUnknownPID            NackReason = 0x0000
+	NackFormatError           NackReason = 0x0001
+	NackHardwareFault         NackReason = 0x0002
+	NackProxyReject           NackReason = 0x0003
+	NackWriteProtect          NackReason = 0x0004
+	NackUnsupportedCommand    NackReason = 0x0005
+	NackDataOutOfRange        NackReason = 0x0006
+	NackBufferFull            NackReason = 0x0007
+	NackPacketSizeUnsupported NackReason = 0x0008
+	NackSubDeviceOutOfRange   NackReason = 0x0009
+	NackProxyBufferFull       NackReason = 0x000A
+)
+
+// Message is an RDM message.
+type Message struct {
+	Destination UID
+	Source      UID
+	Transaction uint8
+
+	// PortID is the port of a request, and the ResponseType of a response.
+	PortID       uint8
+	MessageCount uint8
+	SubDevice    uint16
+
+	CommandClass CommandClass
+	PID          PID
+	Data         []byte
+}
+
+// ResponseType returns the response type of a response message.
+func (m *Message) ResponseType() ResponseType {
+	return ResponseType(m.PortID)
+}
+
+// Err returns an error if m is a response which didn't acknowledge its request.
+func (m *Message) Err() error {
+	switch m.ResponseType() {
+	case ResponseAck, ResponseAckOverflow:
+		return nil
+	case ResponseNackReason:
+		if len(m.Data) >= 2 {
+			return fmt.Errorf("%s NACK: reason 0x%04x", m.PID, NackReason(binary.BigEndian.Uint16(m.Data)))
+		}
+		return fmt.Errorf("%s NACK", m.PID)
+	case ResponseAckTimer:
+		return fmt.Errorf("%s not ready yet", m.PID)
+	default:
+		return fmt.Errorf("%s: unknown response type 0x%02x", m.PID, m.PortID)
+	}
+}
+
+// Responds reports whether m is a response to the request req.
+func (m *Message) Responds(req *Message) bool {
+	return m.CommandClass == req.CommandClass.Response() &&
+		m.PID == req.PID &&
+		m.Transaction == req.Transaction &&
+		m.Source == req.Destination
+}
+
+func (m *Message) Read(r wire.Reader) error {
+	parser := wire.Parse(r)
+	if sc := parser.Int8("StartCode"); parser.Err() == nil && sc != StartCode {
+		parser.Fail("StartCode", fmt.Errorf("unexpected start code 0x%02x", sc))
+	}
+	if sc := parser.Int8("SubStartCode"); parser.Err() == nil && sc != SubStartCode {
+		parser.Fail("SubStartCode", fmt.Errorf("unexpected sub-start code 0x%02x", sc))
+	}
+	length := parser.Int8("Length")
+	copy(m.Destination[:], parser.Bytes("Destination", len(m.Destination)))
+	copy(m.Source[:], parser.Bytes("Source", len(m.Source)))
+	m.Transaction = parser.Int8("Transaction")
+	m.PortID = parser.Int8("PortID")
+	m.MessageCount = parser.Int8("MessageCount")
+	m.SubDevice = parser.Int16("SubDevice", binary.BigEndian)
+	m.CommandClass = CommandClass(parser.Int8("CommandClass"))
+	m.PID = PID(parser.Int16("PID", binary.BigEndian))
+	count := parser.Int8("DataLength")
+	if parser.Err() != nil {
+		return parser.Err()
+	}
+
+	if int(length) != headerLength+int(count) {
+		return &wire.FieldError{Field: "Length", Err: fmt.Errorf("length %d doesn't match data length %d", length, count)}
+	}
+
+	m.Data = parser.Bytes("Data", int(count))
+	checksum := parser.Int16("Checksum", binary.BigEndian)
+	if parser.Err() != nil {
+		return parser.Err()
+	}
+
+	if sum := m.checksum(); sum != checksum {
+		return &wire.FieldError{Field: "Checksum", Err: fmt.Errorf("checksum 0x%04x doesn't match 0x%04x", checksum, sum)}
+	}
+
+	return nil
+}
+
+func (m *Message) Write(w io.Writer) error {
+	if len(m.Data) > MaxParameterData {
+		return &wire.FieldError{Field: "Data", Err: fmt.Errorf("parameter data is longer than %d bytes", MaxParameterData)}
+	}
+
+	buf := bytes.Buffer{}
+	m.header(&buf)
+	buf.Write(m.Data)
+
+	return wire.Build(w).
+		Bytes("Message", buf.Bytes()).
+		Int16("Checksum", sum(buf.Bytes()), binary.BigEndian).
+		Err()
+}
+
+func (m *Message) header(w io.Writer) {
+	wire.Build(w).
+		Int8("StartCode", StartCode).
+		Int8("SubStartCode", SubStartCode).
+		Int8("Length", uint8(headerLength+len(m.Data))).
+		Bytes("Destination", m.Destination[:]).
+		Bytes("Source", m.Source[:]).
+		Int8("Transaction", m.Transaction).
+		Int8("PortID", m.PortID).
+		Int8("MessageCount", m.MessageCount).
+		Int16("SubDevice", m.SubDevice, binary.BigEndian).
+		Int8("CommandClass", uint8(m.CommandClass)).
+		Int16("PID", uint16(m.PID), binary.BigEndian).
+		Int8("DataLength", uint8(len(m.Data)))
+}
+
+func (m *Message) checksum() uint16 {
+	buf := bytes.Buffer{}
+	m.header(&buf)
+	return sum(buf.Bytes()) + sum(m.Data)
+}
+
+func sum(b []byte) uint16 {
+	var s uint16
+	for _, v := range b {
+		s += uint16(v)
+	}
+	return s
+}
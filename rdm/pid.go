@@ -0,0 +1,198 @@
+package rdm
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+)
+
+// PID is an RDM parameter ID.
+type PID uint16
+
+const (
+	PIDDeviceInfo      PID = 0x0060
+	PIDDeviceLabel     PID = 0x0082
+	PIDDMXStartAddress PID = 0x00F0
+	PIDIdentifyDevice  PID = 0x1000
+)
+
+func (p PID) String() string {
+	switch p {
+	case PIDDeviceInfo:
+		return "DEVICE_INFO"
+	case PIDDeviceLabel:
+		return "DEVICE_LABEL"
+	case PIDDMXStartAddress:
+		return "DMX_START_ADDRESS"
+	case PIDIdentifyDevice:
+		return "IDENTIFY_DEVICE"
+	default:
+		return fmt.Sprintf("PID(0x%04x)", uint16(p))
+	}
+}
+
+// maxLabel is the longest label a device accepts.
+const maxLabel = 32
+
+// NewRequest creates a request message for a device's root sub-device.
+func NewRequest(dest, source UID, transaction uint8, class CommandClass, pid PID, data []byte) *Message {
+	return &Message{
+		Destination:  dest,
+		Source:       source,
+		Transaction:  transaction,
+		PortID:       1,
+		CommandClass: class,
+		PID:          pid,
+		Data:         data,
+	}
+}
+
+// GetDeviceInfo creates a DEVICE_INFO request.
+func GetDeviceInfo(dest, source UID, transaction uint8) *Message {
+	return NewRequest(dest, source, transaction, GetCommand, PIDDeviceInfo, nil)
+}
+
+// GetDMXStartAddress creates a request for a device's DMX start address.
+func GetDMXStartAddress(dest, source UID, transaction uint8) *Message {
+	return NewRequest(dest, source, transaction, GetCommand, PIDDMXStartAddress, nil)
+}
+
+// SetDMXStartAddress creates a request to change a device's DMX start
+// address, from 1 to 512.
+func SetDMXStartAddress(dest, source UID, transaction uint8, address uint16) (*Message, error) {
+	if address < 1 || address > 512 {
+		return nil, fmt.Errorf("DMX start address %d is out of range", address)
+	}
+
+	data := make([]byte, 2)
+	binary.BigEndian.PutUint16(data, address)
+	return NewRequest(dest, source, transaction, SetCommand, PIDDMXStartAddress, data), nil
+}
+
+// GetIdentifyDevice creates a request for whether a device is identifying itself.
+func GetIdentifyDevice(dest, source UID, transaction uint8) *Message {
+	return NewRequest(dest, source, transaction, GetCommand, PIDIdentifyDevice, nil)
+}
+
+// SetIdentifyDevice creates a request to start or stop a device identifying itself.
+func SetIdentifyDevice(dest, source UID, transaction uint8, identify bool) *Message {
+	var v byte
+	if identify {
+		v = 1
+	}
+	return NewRequest(dest, source, transaction, SetCommand, PIDIdentifyDevice, []byte{v})
+}
+
+// GetDeviceLabel creates a request for a device's label.
+func GetDeviceLabel(dest, source UID, transaction uint8) *Message {
+	return NewRequest(dest, source, transaction, GetCommand, PIDDeviceLabel, nil)
+}
+
+// SetDeviceLabel creates a request to change a device's label.
+func SetDeviceLabel(dest, source UID, transaction uint8, label string) (*Message, error) {
+	if len(label) > maxLabel {
+		return nil, fmt.Errorf("label is longer than %d bytes", maxLabel)
+	}
+	return NewRequest(dest, source, transaction, SetCommand, PIDDeviceLabel, []byte(label)), nil
+}
+
+// DeviceInfo is the parameter data of a DEVICE_INFO response.
+type DeviceInfo struct {
+	ProtocolVersion uint16
+	Model           uint16
+	ProductCategory uint16
+	SoftwareVersion uint32
+	Footprint       uint16
+	Personality     uint8
+	Personalities   uint8
+	DMXStartAddress uint16
+	SubDeviceCount  uint16
+	SensorCount     uint8
+}
+
+// deviceInfoLength is the length of DEVICE_INFO parameter data.
+const deviceInfoLength = 19
+
+// ParseDeviceInfo decodes the data of a DEVICE_INFO response.
+func ParseDeviceInfo(m *Message) (*DeviceInfo, error) {
+	if err := expect(m, PIDDeviceInfo, deviceInfoLength); err != nil {
+		return nil, err
+	}
+
+	d := m.Data
+	return &DeviceInfo{
+		ProtocolVersion: binary.BigEndian.Uint16(d[0:2]),
+		Model:           binary.BigEndian.Uint16(d[2:4]),
+		ProductCategory: binary.BigEndian.Uint16(d[4:6]),
+		SoftwareVersion: binary.BigEndian.Uint32(d[6:10]),
+		Footprint:       binary.BigEndian.Uint16(d[10:12]),
+		Personality:     d[12],
+		Personalities:   d[13],
+		DMXStartAddress: binary.BigEndian.Uint16(d[14:16]),
+		SubDeviceCount:  binary.BigEndian.Uint16(d[16:18]),
+		SensorCount:     d[18],
+	}, nil
+}
+
+// Bytes encodes the DEVICE_INFO parameter data.
+func (i *DeviceInfo) Bytes() []byte {
+	d := make([]byte, deviceInfoLength)
+	binary.BigEndian.PutUint16(d[0:2], i.ProtocolVersion)
+	binary.BigEndian.PutUint16(d[2:4], i.Model)
+	binary.BigEndian.PutUint16(d[4:6], i.ProductCategory)
+	binary.BigEndian.PutUint32(d[6:10], i.SoftwareVersion)
+	binary.BigEndian.PutUint16(d[10:12], i.Footprint)
+	d[12] = i.Personality
+	d[13] = i.Personalities
+	binary.BigEndian.PutUint16(d[14:16], i.DMXStartAddress)
+	binary.BigEndian.PutUint16(d[16:18], i.SubDeviceCount)
+	d[18] = i.SensorCount
+	return d
+}
+
+// ParseDMXStartAddress decodes the data of a DMX_START_ADDRESS response.
+func ParseDMXStartAddress(m *Message) (uint16, error) {
+	if err := expect(m, PIDDMXStartAddress, 2); err != nil {
+		return 0, err
+	}
+	return binary.BigEndian.Uint16(m.Data), nil
+}
+
+// ParseIdentifyDevice decodes the data of an IDENTIFY_DEVICE response.
+func ParseIdentifyDevice(m *Message) (bool, error) {
+	if err := expect(m, PIDIdentifyDevice, 1); err != nil {
+		return false, err
+	}
+	return m.Data[0] != 0, nil
+}
+
+// ParseDeviceLabel decodes the data of a DEVICE_LABEL response.
+func ParseDeviceLabel(m *Message) (string, error) {
+	if err := expect(m, PIDDeviceLabel, -1); err != nil {
+		return "", err
+	}
+
+	label := m.Data
+	if end := bytes.IndexByte(label, 0); end >= 0 {
+		label = label[:end]
+	}
+	return string(label), nil
+}
+
+// expect checks that m is an acknowledged GET response for pid carrying
+// length bytes of data. A negative length accepts any length.
+func expect(m *Message, pid PID, length int) error {
+	if m.PID != pid {
+		return fmt.Errorf("expected %s response, got %s", pid, m.PID)
+	}
+	if m.CommandClass != GetCommandResponse {
+		return fmt.Errorf("%s: expected GET response, got command class 0x%02x", pid, uint8(m.CommandClass))
+	}
+	if err := m.Err(); err != nil {
+		return err
+	}
+	if length >= 0 && len(m.Data) != length {
+		return fmt.Errorf("%s: expected %d bytes of data, got %d", pid, length, len(m.Data))
+	}
+	return nil
+}
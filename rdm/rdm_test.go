@@ -0,0 +1,287 @@
+package rdm
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"lyra.codes/blinken/artnet/wire"
+)
+
+var (
+	testController = NewUID(0x7a70, 0x00000001)
+	testDevice     = NewUID(0x0001, 0x00000002)
+)
+
+func decodeHex(t *testing.T, s string) []byte {
+	t.Helper()
+
+	b, err := hex.DecodeString(strings.Join(strings.Fields(s), ""))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+func TestMessageWrite(t *testing.T) {
+	m := GetDeviceInfo(testDevice, testController, 3)
+
+	buf := bytes.Buffer{}
+	if err := m.Write(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	want := decodeHex(t, `
+		cc 01 18
+		0001 00000002
+		7a70 00000001
+		03 01 00 0000
+		20 0060 00
+		0257`)
+	if !bytes.Equal(buf.Bytes(), want) {
+		t.Errorf("GET DEVICE_INFO is\n%x, want\n%x", buf.Bytes(), want)
+	}
+}
+
+func TestMessageRoundTrip(t *testing.T) {
+	label, err := SetDeviceLabel(testDevice, testController, 9, "stage left")
+	if err != nil {
+		t.Fatal(err)
+	}
+	address, err := SetDMXStartAddress(testDevice, testController, 10, 0x1ff)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []*Message{
+		GetDeviceInfo(testDevice, testController, 1),
+		SetIdentifyDevice(Broadcast, testController, 2, true),
+		label,
+		address,
+		{
+			Destination:  testController,
+			Source:       testDevice,
+			Transaction:  4,
+			PortID:       uint8(ResponseAck),
+			MessageCount: 2,
+			SubDevice:    7,
+			CommandClass: GetCommandResponse,
+			PID:          PIDDeviceInfo,
+			Data:         bytes.Repeat([]byte{0xff}, MaxParameterData),
+		},
+	}
+
+	for _, m := range tests {
+		buf := bytes.Buffer{}
+		if err := m.Write(&buf); err != nil {
+			t.Fatalf("%s: %v", m.PID, err)
+		}
+
+		got := &Message{}
+		if err := got.Read(&buf); err != nil {
+			t.Fatalf("%s: %v", m.PID, err)
+		}
+		if !reflect.DeepEqual(got, m) {
+			t.Errorf("%s: read %+v, want %+v", m.PID, got, m)
+		}
+	}
+}
+
+func TestMessageReadErrors(t *testing.T) {
+	buf := bytes.Buffer{}
+	if err := GetDeviceLabel(testDevice, testController, 5).Write(&buf); err != nil {
+		t.Fatal(err)
+	}
+	good := buf.Bytes()
+
+	corrupt := func(i int, v byte) []byte {
+		b := append([]byte(nil), good...)
+		b[i] = v
+		return b
+	}
+
+	tests := []struct {
+		name  string
+		data  []byte
+		field string
+	}{
+		{"bad start code", corrupt(0, 0xcd), "StartCode"},
+		{"bad sub-start code", corrupt(1, 0x02), "SubStartCode"},
+		{"bad length", corrupt(2, headerLength+1), "Length"},
+		{"bad checksum", corrupt(len(good)-1, good[len(good)-1]+1), "Checksum"},
+		{"data changed", corrupt(16, 0x30), "Checksum"},
+		{"truncated", good[:len(good)-1], "Checksum"},
+	}
+
+	for _, tt := range tests {
+		err := (&Message{}).Read(bytes.NewBuffer(tt.data))
+		var ferr *wire.FieldError
+		if !errors.As(err, &ferr) {
+			t.Errorf("%s: error is %v, want a FieldError", tt.name, err)
+			continue
+		}
+		if ferr.Field != tt.field {
+			t.Errorf("%s: error is in %s, want %s", tt.name, ferr.Field, tt.field)
+		}
+	}
+}
+
+func TestMessageWriteTooLong(t *testing.T) {
+	m := NewRequest(testDevice, testController, 1, SetCommand, PIDDeviceLabel, make([]byte, MaxParameterData+1))
+	if err := m.Write(&bytes.Buffer{}); err == nil {
+		t.Error("Write accepted too much parameter data")
+	}
+}
+
+func TestSum(t *testing.T) {
+	tests := []struct {
+		data []byte
+		want uint16
+	}{
+		{nil, 0},
+		{[]byte{0x01, 0x02, 0x03}, 0x0006},
+		{bytes.Repeat([]byte{0xff}, 257), 0xffff},
+		{bytes.Repeat([]byte{0xff}, 258), 0x00fe},
+	}
+
+	for _, tt := range tests {
+		if got := sum(tt.data); got != tt.want {
+			t.Errorf("sum of %d bytes is 0x%04x, want 0x%04x", len(tt.data), got, tt.want)
+		}
+	}
+}
+
+func TestRequestData(t *testing.T) {
+	address, err := SetDMXStartAddress(testDevice, testController, 1, 0x0102)
+	if err != nil {
+		t.Fatal(err)
+	}
+	label, err := SetDeviceLabel(testDevice, testController, 1, "wash")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		m     *Message
+		class CommandClass
+		pid   PID
+		data  []byte
+	}{
+		{GetDeviceInfo(testDevice, testController, 1), GetCommand, PIDDeviceInfo, nil},
+		{GetDMXStartAddress(testDevice, testController, 1), GetCommand, PIDDMXStartAddress, nil},
+		{address, SetCommand, PIDDMXStartAddress, []byte{0x01, 0x02}},
+		{GetIdentifyDevice(testDevice, testController, 1), GetCommand, PIDIdentifyDevice, nil},
+		{SetIdentifyDevice(testDevice, testController, 1, true), SetCommand, PIDIdentifyDevice, []byte{1}},
+		{SetIdentifyDevice(testDevice, testController, 1, false), SetCommand, PIDIdentifyDevice, []byte{0}},
+		{GetDeviceLabel(testDevice, testController, 1), GetCommand, PIDDeviceLabel, nil},
+		{label, SetCommand, PIDDeviceLabel, []byte("wash")},
+	}
+
+	for _, tt := range tests {
+		if tt.m.CommandClass != tt.class || tt.m.PID != tt.pid || !bytes.Equal(tt.m.Data, tt.data) {
+			t.Errorf("%s: request is 0x%02x %s %x, want 0x%02x %s %x", tt.pid,
+				uint8(tt.m.CommandClass), tt.m.PID, tt.m.Data, uint8(tt.class), tt.pid, tt.data)
+		}
+	}
+
+	if _, err := SetDMXStartAddress(testDevice, testController, 1, 513); err == nil {
+		t.Error("SetDMXStartAddress accepted 513")
+	}
+	if _, err := SetDeviceLabel(testDevice, testController, 1, strings.Repeat("x", maxLabel+1)); err == nil {
+		t.Error("SetDeviceLabel accepted a label that's too long")
+	}
+}
+
+func response(pid PID, data []byte) *Message {
+	return &Message{
+		Destination:  testController,
+		Source:       testDevice,
+		CommandClass: GetCommandResponse,
+		PortID:       uint8(ResponseAck),
+		PID:          pid,
+		Data:         data,
+	}
+}
+
+func TestParseResponses(t *testing.T) {
+	info := &DeviceInfo{
+		ProtocolVersion: 0x0100,
+		Model:           0x1234,
+		ProductCategory: 0x0101,
+		SoftwareVersion: 0x01020304,
+		Footprint:       4,
+		Personality:     1,
+		Personalities:   2,
+		DMXStartAddress: 17,
+		SensorCount:     1,
+	}
+	got, err := ParseDeviceInfo(response(PIDDeviceInfo, info.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, info) {
+		t.Errorf("DEVICE_INFO is %+v, want %+v", got, info)
+	}
+
+	if a, err := ParseDMXStartAddress(response(PIDDMXStartAddress, []byte{0x01, 0x02})); err != nil || a != 0x0102 {
+		t.Errorf("DMX_START_ADDRESS is %d, %v, want 258", a, err)
+	}
+	if on, err := ParseIdentifyDevice(response(PIDIdentifyDevice, []byte{1})); err != nil || !on {
+		t.Errorf("IDENTIFY_DEVICE is %v, %v, want true", on, err)
+	}
+	if l, err := ParseDeviceLabel(response(PIDDeviceLabel, []byte("wash\x00\x00"))); err != nil || l != "wash" {
+		t.Errorf("DEVICE_LABEL is %q, %v, want wash", l, err)
+	}
+
+	nack := response(PIDDMXStartAddress, []byte{0x00, byte(NackDataOutOfRange)})
+	nack.PortID = uint8(ResponseNackReason)
+
+	errs := []struct {
+		name string
+		m    *Message
+	}{
+		{"wrong PID", response(PIDDeviceLabel, []byte{0x01, 0x02})},
+		{"wrong length", response(PIDDMXStartAddress, []byte{0x01})},
+		{"NACK", nack},
+		{"SET response", &Message{CommandClass: SetCommandResponse, PID: PIDDMXStartAddress, Data: []byte{0x01, 0x02}}},
+	}
+	for _, tt := range errs {
+		if _, err := ParseDMXStartAddress(tt.m); err == nil {
+			t.Errorf("%s: ParseDMXStartAddress succeeded", tt.name)
+		}
+	}
+}
+
+func TestResponds(t *testing.T) {
+	req := GetDeviceInfo(testDevice, testController, 7)
+	resp := response(PIDDeviceInfo, nil)
+	resp.Transaction = 7
+
+	if !resp.Responds(req) {
+		t.Error("response doesn't respond to its request")
+	}
+
+	resp.Transaction = 8
+	if resp.Responds(req) {
+		t.Error("response with another transaction responds to the request")
+	}
+}
+
+func TestUID(t *testing.T) {
+	u, err := ParseUID("7a70:0000002a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if u.Manufacturer() != 0x7a70 || u.Device() != 0x2a || u.String() != "7a70:0000002a" {
+		t.Errorf("parsed UID %s", u)
+	}
+	if u.IsBroadcast() || !Broadcast.IsBroadcast() || !NewUID(0x7a70, 0xffffffff).IsBroadcast() {
+		t.Error("IsBroadcast is wrong")
+	}
+	if _, err := ParseUID("7a70"); err == nil {
+		t.Error("ParseUID accepted 7a70")
+	}
+}
@@ -0,0 +1,49 @@
+package rdm
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+// UID is the unique ID of an RDM device: a 16-bit ESTA manufacturer ID
+// followed by a 32-bit device ID.
+type UID [6]byte
+
+// Broadcast is the UID that addresses every device.
+var Broadcast = NewUID(0xFFFF, 0xFFFFFFFF)
+
+// NewUID creates a UID from its manufacturer and device IDs.
+func NewUID(manufacturer uint16, device uint32) UID {
+	var u UID
+	binary.BigEndian.PutUint16(u[0:2], manufacturer)
+	binary.BigEndian.PutUint32(u[2:6], device)
+	return u
+}
+
+// ParseUID parses a UID written as "mmmm:dddddddd" in hexadecimal.
+func ParseUID(s string) (UID, error) {
+	var manufacturer uint16
+	var device uint32
+	if n, err := fmt.Sscanf(s, "%04x:%08x", &manufacturer, &device); err != nil || n != 2 {
+		return UID{}, fmt.Errorf("invalid UID %q", s)
+	}
+	return NewUID(manufacturer, device), nil
+}
+
+func (u UID) Manufacturer() uint16 {
+	return binary.BigEndian.Uint16(u[0:2])
+}
+
+func (u UID) Device() uint32 {
+	return binary.BigEndian.Uint32(u[2:6])
+}
+
+// IsBroadcast reports whether the UID addresses all devices, or all
+// devices made by one manufacturer.
+func (u UID) IsBroadcast() bool {
+	return u.Device() == 0xFFFFFFFF
+}
+
+func (u UID) String() string {
+	return fmt.Sprintf("%04x:%08x", u.Manufacturer(), u.Device())
+}
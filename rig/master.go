@@ -0,0 +1,102 @@
+package rig
+
+import (
+	"fmt"
+	"sync"
+
+	"lyra.codes/blinken/dmx"
+)
+
+// Masters scale the intensity of a rig: a grand master for everything,
+// group masters for named groups, and a blackout which turns everything
+// off. They are safe to change while another goroutine renders.
+type Masters struct {
+	mu       sync.Mutex
+	grand    float64
+	groups   map[string]float64
+	blackout bool
+}
+
+// NewMasters creates masters with every level at full.
+func NewMasters() *Masters {
+	return &Masters{grand: 1, groups: make(map[string]float64)}
+}
+
+// SetGrand sets the grand master, from 0 to 1.
+func (m *Masters) SetGrand(level float64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.grand = dmx.ClampLevel(level)
+}
+
+// Grand returns the grand master.
+func (m *Masters) Grand() float64 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.grand
+}
+
+// SetGroup sets the master of a group, from 0 to 1.
+func (m *Masters) SetGroup(name string, level float64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.groups[name] = dmx.ClampLevel(level)
+}
+
+// Group returns the master of a group, which is full unless it has been
+// set.
+func (m *Masters) Group(name string) float64 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if level, ok := m.groups[name]; ok {
+		return level
+	}
+	return 1
+}
+
+// SetBlackout turns the blackout on or off.
+func (m *Masters) SetBlackout(on bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.blackout = on
+}
+
+// Blackout reports whether the blackout is on.
+func (m *Masters) Blackout() bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.blackout
+}
+
+// Level returns the level something in the groups is scaled by: the grand
+// master multiplied by each of the group masters, or zero during a
+// blackout. Nil masters are always at full.
+func (m *Masters) Level(groups ...string) float64 {
+	if m == nil {
+		return 1
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.blackout {
+		return 0
+	}
+	level := m.grand
+	for _, name := range groups {
+		if l, ok := m.groups[name]; ok {
+			level *= l
+		}
+	}
+	return level
+}
+
+func (m *Masters) String() string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.blackout {
+		return "blackout"
+	}
+	return fmt.Sprintf("grand master %.0f%%", m.grand*100)
+}
@@ -0,0 +1,109 @@
+package rig
+
+import (
+	"bytes"
+	"testing"
+
+	"lyra.codes/blinken/dmx"
+)
+
+func TestMastersLevel(t *testing.T) {
+	m := NewMasters()
+	m.SetGroup("front", 0.5)
+	m.SetGroup("wash", 0.5)
+	m.SetGroup("over", 2)
+	m.SetGroup("under", -1)
+
+	tests := []struct {
+		name   string
+		grand  float64
+		groups []string
+		want   float64
+	}{
+		{"full", 1, nil, 1},
+		{"grand master", 0.5, nil, 0.5},
+		{"group master", 1, []string{"front"}, 0.5},
+		{"group masters multiply", 1, []string{"front", "wash"}, 0.25},
+		{"with the grand master", 0.5, []string{"front", "wash"}, 0.125},
+		{"unset group is full", 1, []string{"back"}, 1},
+		{"clamped over full", 1, []string{"over"}, 1},
+		{"clamped under zero", 1, []string{"under"}, 0},
+	}
+
+	for _, tt := range tests {
+		m.SetGrand(tt.grand)
+		if got := m.Level(tt.groups...); got != tt.want {
+			t.Errorf("%s: level is %v, want %v", tt.name, got, tt.want)
+		}
+	}
+
+	m.SetGrand(1)
+	m.SetBlackout(true)
+	if got := m.Level(); got != 0 {
+		t.Errorf("level during a blackout is %v", got)
+	}
+	if m.String() != "blackout" {
+		t.Errorf("masters during a blackout are %s", m)
+	}
+	m.SetBlackout(false)
+	if got := m.Level("front"); got != 0.5 {
+		t.Errorf("level after a blackout is %v, want 0.5", got)
+	}
+
+	var none *Masters
+	if got := none.Level("front"); got != 1 {
+		t.Errorf("nil masters have level %v", got)
+	}
+}
+
+func TestRenderMasters(t *testing.T) {
+	mover := &dmx.FixtureProfile{Name: "Mover", Channels: []dmx.ProfileChannel{
+		{Role: dmx.Pan}, {Role: dmx.Intensity}, {Role: dmx.Red},
+	}}
+
+	p := NewPatch()
+	p.Masters = NewMasters()
+	if _, err := p.Add("spot", 1, 1, mover); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := p.Add("par", 1, 4, dmx.ProfileRGB); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Group("front", "spot", "par"); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Group("pars", "par"); err != nil {
+		t.Fatal(err)
+	}
+
+	state := State{"front": {dmx.Pan: 1, dmx.Intensity: 1, dmx.Red: 1}}
+
+	tests := []struct {
+		name     string
+		grand    float64
+		front    float64
+		pars     float64
+		blackout bool
+		want     dmx.Universe
+	}{
+		{"full", 1, 1, 1, false, dmx.Universe{255, 255, 255, 255, 0, 0}},
+		{"grand master", 0.5, 1, 1, false, dmx.Universe{255, 128, 255, 128, 0, 0}},
+		{"group masters", 1, 0.5, 0.5, false, dmx.Universe{255, 128, 255, 64, 0, 0}},
+		{"blackout", 1, 1, 1, true, dmx.Universe{255, 0, 255, 0, 0, 0}},
+	}
+
+	for _, tt := range tests {
+		p.Masters.SetGrand(tt.grand)
+		p.Masters.SetGroup("front", tt.front)
+		p.Masters.SetGroup("pars", tt.pars)
+		p.Masters.SetBlackout(tt.blackout)
+
+		frame, err := p.Render(state)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := frame[1][:len(tt.want)]; !bytes.Equal(got, tt.want) {
+			t.Errorf("%s: universe is %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,260 @@
+// Package rig patches fixtures into Art-Net universes, and renders the state
+// of a rig into DMX data.
+package rig
+
+import (
+	"fmt"
+	"sort"
+
+	"lyra.codes/blinken/artnet"
+	"lyra.codes/blinken/dmx"
+)
+
+// Fixture is a named fixture patched into a universe.
+type Fixture struct {
+	dmx.Fixture
+
+	Name     string
+	Universe artnet.Address
+
+	// Power, if set, estimates the power the fixture draws from Supply,
+	// for the patch's Limiter.
+	Power  *PowerModel
+	Supply string
+}
+
+// End returns the fixture's last channel.
+func (f *Fixture) End() int {
+	return f.Address + f.Profile.Footprint() - 1
+}
+
+func (f *Fixture) String() string {
+	return fmt.Sprintf("%s (%s at %s/%d)", f.Name, f.Profile.Name, f.Universe, f.Address)
+}
+
+// Patch maps named fixtures and groups of fixtures to their addresses.
+// Fixtures and groups share one set of names.
+type Patch struct {
+	// Masters, if set, dim fixtures as they're rendered.
+	Masters *Masters
+
+	// Limiter, if set, limits the power drawn by fixtures with a power
+	// model as they're rendered. The patch is limited on its own, so its
+	// fixtures' supplies shouldn't power anything else, such as strips.
+	Limiter *Limiter
+	report  Report
+
+	fixtures []*Fixture
+	byName   map[string]*Fixture
+
+	groups     map[string][]*Fixture
+	groupNames []string
+	memberOf   map[*Fixture][]string
+}
+
+// NewPatch creates an empty patch.
+func NewPatch() *Patch {
+	return &Patch{
+		byName:   make(map[string]*Fixture),
+		groups:   make(map[string][]*Fixture),
+		memberOf: make(map[*Fixture][]string),
+	}
+}
+
+// Add patches a fixture at an address in a universe. It fails if the fixture
+// runs past the end of the universe, or overlaps a fixture already patched.
+func (p *Patch) Add(name string, universe artnet.Address, address int, profile *dmx.FixtureProfile) (*Fixture, error) {
+	if p.taken(name) {
+		return nil, fmt.Errorf("%s is already patched", name)
+	}
+
+	fixture, err := dmx.NewFixture(profile, address)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", name, err)
+	}
+
+	f := &Fixture{Fixture: *fixture, Name: name, Universe: universe}
+	for _, other := range p.fixtures {
+		if other.Universe == f.Universe && other.Address <= f.End() && f.Address <= other.End() {
+			return nil, fmt.Errorf("%s overlaps %s", f, other)
+		}
+	}
+
+	p.fixtures = append(p.fixtures, f)
+	p.byName[name] = f
+	return f, nil
+}
+
+// Group names a group of fixtures. Members are fixtures or groups already in
+// the patch.
+func (p *Patch) Group(name string, members ...string) error {
+	if p.taken(name) {
+		return fmt.Errorf("%s is already patched", name)
+	}
+
+	var fixtures []*Fixture
+	seen := make(map[*Fixture]bool)
+	for _, member := range members {
+		fs, err := p.Members(member)
+		if err != nil {
+			return fmt.Errorf("group %s: %v", name, err)
+		}
+
+		for _, f := range fs {
+			if !seen[f] {
+				seen[f] = true
+				fixtures = append(fixtures, f)
+				p.memberOf[f] = append(p.memberOf[f], name)
+			}
+		}
+	}
+
+	p.groups[name] = fixtures
+	p.groupNames = append(p.groupNames, name)
+	return nil
+}
+
+func (p *Patch) taken(name string) bool {
+	_, fixture := p.byName[name]
+	_, group := p.groups[name]
+	return fixture || group
+}
+
+// Fixture returns the fixture with a name.
+func (p *Patch) Fixture(name string) (*Fixture, bool) {
+	f, ok := p.byName[name]
+	return f, ok
+}
+
+// Fixtures returns every fixture in the order they were patched.
+func (p *Patch) Fixtures() []*Fixture {
+	return append([]*Fixture(nil), p.fixtures...)
+}
+
+// Members returns the fixtures in a group, or the fixture with the name.
+func (p *Patch) Members(name string) ([]*Fixture, error) {
+	if f, ok := p.byName[name]; ok {
+		return []*Fixture{f}, nil
+	}
+	if fs, ok := p.groups[name]; ok {
+		return append([]*Fixture(nil), fs...), nil
+	}
+	return nil, fmt.Errorf("no fixture or group named %s", name)
+}
+
+// Universes returns the universes fixtures are patched into, in order.
+func (p *Patch) Universes() []artnet.Address {
+	seen := make(map[artnet.Address]bool)
+	var addrs []artnet.Address
+	for _, f := range p.fixtures {
+		if !seen[f.Universe] {
+			seen[f.Universe] = true
+			addrs = append(addrs, f.Universe)
+		}
+	}
+
+	sort.Slice(addrs, func(i, j int) bool { return addrs[i] < addrs[j] })
+	return addrs
+}
+
+// State holds params for fixtures and groups, by name.
+type State map[string]dmx.Params
+
+// Frame is the DMX data of every universe in a rig.
+type Frame map[artnet.Address]dmx.Universe
+
+// Universes returns the frame's universes, in order.
+func (f Frame) Universes() []artnet.Address {
+	addrs := make([]artnet.Address, 0, len(f))
+	for addr := range f {
+		addrs = append(addrs, addr)
+	}
+
+	sort.Slice(addrs, func(i, j int) bool { return addrs[i] < addrs[j] })
+	return addrs
+}
+
+// Render writes the state of the rig into a frame holding every universe
+// with fixtures patched into it.
+//
+// Params for groups are applied first, in the order the groups were named,
+// followed by params for single fixtures, so the most specific params win.
+// Fixtures without params are set to their defaults. Finally, the masters
+// dim each fixture by the grand master and the masters of its groups, and
+// the limiter limits their power.
+func (p *Patch) Render(state State) (Frame, error) {
+	params := make(map[*Fixture]dmx.Params, len(p.fixtures))
+	merge := func(f *Fixture, from dmx.Params) {
+		to := params[f]
+		if to == nil {
+			to = make(dmx.Params, len(from))
+			params[f] = to
+		}
+		for role, v := range from {
+			to[role] = v
+		}
+	}
+
+	for name := range state {
+		if !p.taken(name) {
+			return nil, fmt.Errorf("no fixture or group named %s", name)
+		}
+	}
+	for _, name := range p.groupNames {
+		if s, ok := state[name]; ok {
+			for _, f := range p.groups[name] {
+				merge(f, s)
+			}
+		}
+	}
+	for _, f := range p.fixtures {
+		if s, ok := state[f.Name]; ok {
+			merge(f, s)
+		}
+	}
+
+	frame := make(Frame)
+	for _, addr := range p.Universes() {
+		frame[addr] = make(dmx.Universe, dmx.UniverseSize)
+	}
+
+	rendered := make([]dmx.Params, len(p.fixtures))
+	var loads []FixtureLoad
+	var powered []int
+	for i, f := range p.fixtures {
+		s := params[f]
+		if p.Masters != nil {
+			s = f.Profile.Dim(s, p.Masters.Level(p.memberOf[f]...))
+		}
+		rendered[i] = s
+
+		if p.Limiter != nil && f.Power != nil {
+			loads = append(loads, FixtureLoad{Supply: f.Supply, Model: *f.Power, Profile: f.Profile, Params: s})
+			powered = append(powered, i)
+		}
+	}
+
+	if p.Limiter != nil {
+		report, err := p.Limiter.LimitFixtures(loads)
+		if err != nil {
+			return nil, err
+		}
+		for j, i := range powered {
+			rendered[i] = loads[j].Params
+		}
+		p.report = report
+	}
+
+	for i, f := range p.fixtures {
+		if err := f.Set(frame[f.Universe], rendered[i]); err != nil {
+			return nil, fmt.Errorf("%s: %v", f.Name, err)
+		}
+	}
+
+	return frame, nil
+}
+
+// PowerReport returns the limiting applied to the last frame rendered.
+func (p *Patch) PowerReport() Report {
+	return p.report
+}
@@ -0,0 +1,187 @@
+package rig
+
+import (
+	"bytes"
+	"testing"
+
+	"lyra.codes/blinken/artnet"
+	"lyra.codes/blinken/dmx"
+)
+
+// patchEntry is a fixture to patch, and whether patching it should fail.
+type patchEntry struct {
+	name     string
+	universe artnet.Address
+	address  int
+	profile  *dmx.FixtureProfile
+	fails    bool
+}
+
+func TestPatchAdd(t *testing.T) {
+	tests := []struct {
+		name    string
+		entries []patchEntry
+	}{
+		{
+			name: "adjacent fixtures",
+			entries: []patchEntry{
+				{"a", 1, 1, dmx.ProfileRGB, false},
+				{"b", 1, 4, dmx.ProfileRGB, false},
+				{"c", 1, 7, dmx.ProfileRGBW, false},
+			},
+		},
+		{
+			name: "overlapping the start",
+			entries: []patchEntry{
+				{"a", 1, 10, dmx.ProfileRGB, false},
+				{"b", 1, 8, dmx.ProfileRGB, true},
+			},
+		},
+		{
+			name: "overlapping the end",
+			entries: []patchEntry{
+				{"a", 1, 10, dmx.ProfileRGB, false},
+				{"b", 1, 12, dmx.ProfileRGB, true},
+			},
+		},
+		{
+			name: "inside another",
+			entries: []patchEntry{
+				{"a", 1, 10, dmx.ProfileRGBW16, false},
+				{"b", 1, 12, dmx.ProfileDimmer, true},
+			},
+		},
+		{
+			name: "same address in another universe",
+			entries: []patchEntry{
+				{"a", 1, 1, dmx.ProfileRGB, false},
+				{"b", 2, 1, dmx.ProfileRGB, false},
+			},
+		},
+		{
+			name: "past the end of the universe",
+			entries: []patchEntry{
+				{"a", 1, 511, dmx.ProfileRGB, true},
+				{"b", 1, 510, dmx.ProfileRGB, false},
+			},
+		},
+		{
+			name: "duplicate name",
+			entries: []patchEntry{
+				{"a", 1, 1, dmx.ProfileRGB, false},
+				{"a", 2, 1, dmx.ProfileRGB, true},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		p := NewPatch()
+		for _, e := range tt.entries {
+			_, err := p.Add(e.name, e.universe, e.address, e.profile)
+			if failed := err != nil; failed != e.fails {
+				t.Errorf("%s: adding %s at %d/%d: error %v", tt.name, e.name, e.universe, e.address, err)
+			}
+		}
+	}
+}
+
+func TestPatchGroupNames(t *testing.T) {
+	p := NewPatch()
+	if _, err := p.Add("a", 1, 1, dmx.ProfileRGB); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Group("front", "a"); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := p.Group("a", "a"); err == nil {
+		t.Error("group took a fixture's name")
+	}
+	if err := p.Group("front", "a"); err == nil {
+		t.Error("group took another group's name")
+	}
+	if _, err := p.Add("front", 1, 10, dmx.ProfileRGB); err == nil {
+		t.Error("fixture took a group's name")
+	}
+	if err := p.Group("back", "missing"); err == nil {
+		t.Error("group has a missing member")
+	}
+}
+
+func TestRender(t *testing.T) {
+	p := NewPatch()
+	for _, e := range []patchEntry{
+		{"left", 1, 1, dmx.ProfileRGB, false},
+		{"right", 1, 4, dmx.ProfileRGB, false},
+		{"back", 2, 1, dmx.ProfileDimmer, false},
+	} {
+		if _, err := p.Add(e.name, e.universe, e.address, e.profile); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := p.Group("front", "left", "right"); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Group("all", "front", "back"); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name  string
+		state State
+		want1 dmx.Universe
+		want2 dmx.Universe
+	}{
+		{
+			name:  "defaults",
+			state: State{},
+			want1: dmx.Universe{0, 0, 0, 0, 0, 0},
+			want2: dmx.Universe{0},
+		},
+		{
+			name:  "group",
+			state: State{"front": {dmx.Red: 1}},
+			want1: dmx.Universe{255, 0, 0, 255, 0, 0},
+			want2: dmx.Universe{0},
+		},
+		{
+			name:  "fixture over group",
+			state: State{"front": {dmx.Red: 1, dmx.Blue: 1}, "right": {dmx.Red: 0}},
+			want1: dmx.Universe{255, 0, 255, 0, 0, 255},
+			want2: dmx.Universe{0},
+		},
+		{
+			// all was named after front, so it's applied after.
+			name:  "groups in the order they were named",
+			state: State{"all": {dmx.Red: 1, dmx.Intensity: 1}, "front": {dmx.Red: 0, dmx.Green: 1}},
+			want1: dmx.Universe{255, 255, 0, 255, 255, 0},
+			want2: dmx.Universe{255},
+		},
+		{
+			name:  "fixture over both groups",
+			state: State{"all": {dmx.Blue: 1}, "front": {dmx.Blue: 0}, "left": {dmx.Blue: 0.5}},
+			want1: dmx.Universe{0, 0, 128, 0, 0, 255},
+			want2: dmx.Universe{0},
+		},
+	}
+
+	for _, tt := range tests {
+		frame, err := p.Render(tt.state)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if len(frame) != 2 || len(frame[1]) != dmx.UniverseSize {
+			t.Fatalf("%s: frame has %d universes", tt.name, len(frame))
+		}
+		if got := frame[1][:len(tt.want1)]; !bytes.Equal(got, tt.want1) {
+			t.Errorf("%s: universe 1 is %v, want %v", tt.name, got, tt.want1)
+		}
+		if got := frame[2][:len(tt.want2)]; !bytes.Equal(got, tt.want2) {
+			t.Errorf("%s: universe 2 is %v, want %v", tt.name, got, tt.want2)
+		}
+	}
+
+	if _, err := p.Render(State{"missing": {dmx.Red: 1}}); err == nil {
+		t.Error("Render accepted state for a missing fixture")
+	}
+}
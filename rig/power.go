@@ -0,0 +1,398 @@
+package rig
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"lyra.codes/blinken/color"
+	"lyra.codes/blinken/dmx"
+)
+
+// PowerModel estimates the power a fixture or pixel draws, in watts.
+type PowerModel struct {
+	// Watts is the power each role's emitters draw at full, such as Red
+	// or White. Pixels without a white channel mix white from red, green
+	// and blue, so they draw the power of those for white. A pixel with
+	// two white channels draws Watts[White] for both together.
+	//
+	// Watts[Intensity] is the power of a fixture's lamp, which its
+	// intensity channel dims; a fixture with an intensity channel also
+	// dims the power of its colors by it. Watts of other roles, and of
+	// roles a fixture or pixel has no channel for, are ignored.
+	Watts map[dmx.Role]float64
+
+	// Idle is the power drawn when dark.
+	Idle float64
+}
+
+// addPixel adds the power of each role of a pixel showing a color to draws.
+func (m PowerModel) addPixel(draws map[dmx.Role]float64, layout dmx.PixelLayout, c color.RGBW) {
+	for role, level := range layout.Levels(c) {
+		if w := m.Watts[role]; w != 0 && role.Colored() {
+			draws[role] += level * w
+		}
+	}
+}
+
+// addFixture adds the power of each role of a fixture with params to draws.
+func (m PowerModel) addFixture(draws map[dmx.Role]float64, profile *dmx.FixtureProfile, params dmx.Params) {
+	intensity := 1.0
+	if profile.Has(dmx.Intensity) {
+		intensity = profile.Level(params, dmx.Intensity)
+		if w := m.Watts[dmx.Intensity]; w != 0 {
+			draws[dmx.Intensity] += intensity * w
+		}
+	}
+
+	for role, w := range m.Watts {
+		if w != 0 && role.Colored() && profile.Has(role) {
+			draws[role] += intensity * profile.Level(params, role) * w
+		}
+	}
+}
+
+// Limiting is how a Limiter brings the power drawn from a supply within its
+// budget.
+type Limiting uint8
+
+const (
+	// Proportional scales every load on the supply by the same amount,
+	// keeping their hues.
+	Proportional Limiting = iota
+
+	// PerChannel shares the budget between the roles drawing power, such
+	// as red, green, blue and white, scaling down only the roles drawing
+	// more than their share. It keeps more light than Proportional, but
+	// shifts hues.
+	PerChannel
+)
+
+func (l Limiting) String() string {
+	switch l {
+	case Proportional:
+		return "proportional"
+	case PerChannel:
+		return "per channel"
+	default:
+		return fmt.Sprintf("Limiting(%d)", uint8(l))
+	}
+}
+
+// Load is the colors of pixels drawing power from a supply, such as the
+// pixels of a strip. Model is the power of each pixel.
+type Load struct {
+	Supply string
+	Model  PowerModel
+	Layout dmx.PixelLayout
+	Colors []color.RGBW
+}
+
+// FixtureLoad is the params of a fixture drawing power from a supply.
+type FixtureLoad struct {
+	Supply  string
+	Model   PowerModel
+	Profile *dmx.FixtureProfile
+	Params  dmx.Params
+}
+
+// Limiter scales each frame so the estimated power drawn from each power
+// supply stays within its budget.
+type Limiter struct {
+	Limiting Limiting
+
+	budgets map[string]float64
+}
+
+// NewLimiter creates a limiter without any supplies.
+func NewLimiter(limiting Limiting) *Limiter {
+	return &Limiter{Limiting: limiting, budgets: make(map[string]float64)}
+}
+
+// AddSupply adds a power supply with a budget in watts.
+func (l *Limiter) AddSupply(name string, budget float64) error {
+	if _, ok := l.budgets[name]; ok {
+		return fmt.Errorf("power supply %s already added", name)
+	}
+	if budget < 0 {
+		return fmt.Errorf("power supply %s has a budget of %.1fW", name, budget)
+	}
+
+	l.budgets[name] = budget
+	return nil
+}
+
+// Limit scales the colors of pixel loads, in place, so that each supply's
+// estimated draw is within its budget, and reports the limiting applied.
+// Levels over full are clamped, as they were estimated, and the colors of
+// limited pixels without a white channel have their white mixed into red,
+// green and blue.
+//
+// Idle power can't be limited, so a supply whose idle power is over budget
+// has its loads turned off and stays over.
+//
+// The amber of a pixel follows its red and green, so PerChannel limiting
+// scales the red and green of pixels with amber by amber's scale if that is
+// lower.
+func (l *Limiter) Limit(loads []Load) (Report, error) {
+	ss := make(supplies)
+	for _, load := range loads {
+		s, err := l.supply(ss, load.Supply)
+		if err != nil {
+			return Report{}, err
+		}
+
+		s.report.Demand += load.Model.Idle * float64(len(load.Colors))
+		for _, c := range load.Colors {
+			load.Model.addPixel(s.draws, load.Layout, c)
+		}
+	}
+	report := l.report(ss)
+
+	for _, load := range loads {
+		s := ss[load.Supply]
+		if !s.limited {
+			continue
+		}
+
+		r, g := s.scale(dmx.Red), s.scale(dmx.Green)
+		if load.Layout.Has(dmx.Amber) {
+			if a := s.scale(dmx.Amber); a < r {
+				r = a
+			}
+			if a := s.scale(dmx.Amber); a < g {
+				g = a
+			}
+		}
+		for i, c := range load.Colors {
+			c = load.Layout.Mix(c)
+			load.Colors[i] = color.RGBW{
+				R: dmx.ClampLevel(c.R) * r,
+				G: dmx.ClampLevel(c.G) * g,
+				B: dmx.ClampLevel(c.B) * s.scale(dmx.Blue),
+				W: dmx.ClampLevel(c.W) * s.scale(dmx.White),
+			}
+		}
+	}
+
+	return report, nil
+}
+
+// LimitFixtures replaces the params of fixture loads with limited ones so
+// that each supply's estimated draw is within its budget, and reports the
+// limiting applied. Like Limit, it can't limit idle power.
+//
+// Proportional limiting dims fixtures as masters do: by their intensity
+// channel if they have one, otherwise by their colors. PerChannel limiting
+// scales the colors and intensity of each fixture by their roles' scales.
+// The power of a fixture's colors is dimmed by its intensity too, so
+// scaling both can leave fixtures further under budget than needed.
+func (l *Limiter) LimitFixtures(loads []FixtureLoad) (Report, error) {
+	ss := make(supplies)
+	for _, load := range loads {
+		s, err := l.supply(ss, load.Supply)
+		if err != nil {
+			return Report{}, err
+		}
+
+		s.report.Demand += load.Model.Idle
+		load.Model.addFixture(s.draws, load.Profile, load.Params)
+	}
+	report := l.report(ss)
+
+	for i, load := range loads {
+		s := ss[load.Supply]
+		if !s.limited {
+			continue
+		}
+
+		if l.Limiting == Proportional || s.proportion == 0 {
+			loads[i].Params = load.Profile.Dim(load.Params, s.proportion)
+			continue
+		}
+
+		params := make(dmx.Params, len(load.Params))
+		for role, v := range load.Params {
+			params[role] = v
+		}
+		for role, scale := range s.scales {
+			if (role == dmx.Intensity || role.Colored()) && load.Profile.Has(role) {
+				params[role] = load.Profile.Level(load.Params, role) * scale
+			}
+		}
+		loads[i].Params = params
+	}
+
+	return report, nil
+}
+
+// supply is the power drawn from one supply while limiting a frame.
+type supply struct {
+	report SupplyReport
+
+	// draws is the power each role draws before limiting, and scales is
+	// how much each is scaled by. Proportional limiting scales every role
+	// by proportion.
+	draws      map[dmx.Role]float64
+	scales     map[dmx.Role]float64
+	proportion float64
+	limited    bool
+}
+
+type supplies map[string]*supply
+
+// scale returns how much a role is scaled by.
+func (s *supply) scale(role dmx.Role) float64 {
+	if scale, ok := s.scales[role]; ok {
+		return scale
+	}
+	return s.proportion
+}
+
+// supply returns the supply with a name, starting it if it's new to the
+// frame.
+func (l *Limiter) supply(ss supplies, name string) (*supply, error) {
+	if s, ok := ss[name]; ok {
+		return s, nil
+	}
+
+	budget, ok := l.budgets[name]
+	if !ok {
+		return nil, fmt.Errorf("no power supply named %s", name)
+	}
+
+	s := &supply{
+		report: SupplyReport{Name: name, Budget: budget},
+		draws:  make(map[dmx.Role]float64),
+	}
+	ss[name] = s
+	return s, nil
+}
+
+// report works out the scales of each supply from their draws, and reports
+// them by name.
+func (l *Limiter) report(ss supplies) Report {
+	report := Report{Supplies: make([]SupplyReport, 0, len(ss))}
+	for _, s := range ss {
+		idle := s.report.Demand
+		total := 0.0
+		for _, d := range s.draws {
+			total += d
+		}
+		s.report.Demand += total
+
+		available := s.report.Budget - idle
+		s.proportion = 1
+		s.scales = make(map[dmx.Role]float64, len(s.draws))
+		switch {
+		case total <= available:
+		case available <= 0:
+			s.proportion = 0
+		case l.Limiting == Proportional:
+			s.proportion = available / total
+		default:
+			shareRoles(s.draws, available, s.scales)
+		}
+
+		s.report.Draw = idle
+		s.limited = s.proportion < 1
+		for role, d := range s.draws {
+			if _, ok := s.scales[role]; !ok {
+				s.scales[role] = s.proportion
+			}
+			s.report.Draw += d * s.scales[role]
+			s.limited = s.limited || s.scales[role] < 1
+		}
+		s.report.Scale = s.scales
+
+		report.Supplies = append(report.Supplies, s.report)
+	}
+
+	sort.Slice(report.Supplies, func(i, j int) bool { return report.Supplies[i].Name < report.Supplies[j].Name })
+	return report
+}
+
+// shareRoles shares available watts equally between roles drawing power,
+// giving what roles drawing less than their share don't need to the others,
+// and sets how much each role is scaled by in scales.
+func shareRoles(draws map[dmx.Role]float64, available float64, scales map[dmx.Role]float64) {
+	roles := make([]dmx.Role, 0, len(draws))
+	for role := range draws {
+		roles = append(roles, role)
+	}
+	sort.Slice(roles, func(i, j int) bool {
+		if draws[roles[i]] != draws[roles[j]] {
+			return draws[roles[i]] < draws[roles[j]]
+		}
+		return roles[i] < roles[j]
+	})
+
+	for n, role := range roles {
+		share := available / float64(len(roles)-n)
+		if d := draws[role]; d > share {
+			scales[role] = share / d
+			available -= share
+		} else {
+			scales[role] = 1
+			available -= d
+		}
+	}
+}
+
+// Report is the limiting a Limiter applied to a frame.
+type Report struct {
+	Supplies []SupplyReport
+}
+
+// Limited reports whether any supply was limited.
+func (r Report) Limited() bool {
+	for _, s := range r.Supplies {
+		if s.Limited() {
+			return true
+		}
+	}
+	return false
+}
+
+func (r Report) String() string {
+	parts := make([]string, len(r.Supplies))
+	for i, s := range r.Supplies {
+		parts[i] = s.String()
+	}
+	return strings.Join(parts, "; ")
+}
+
+// SupplyReport is the limiting applied to one power supply.
+type SupplyReport struct {
+	Name   string
+	Budget float64
+
+	// Demand is the estimated draw before limiting, and Draw is after.
+	Demand float64
+	Draw   float64
+
+	// Scale is how much each role drawing power was scaled by.
+	Scale map[dmx.Role]float64
+}
+
+// Limited reports whether the supply's loads were scaled down.
+func (s SupplyReport) Limited() bool {
+	return s.Draw < s.Demand
+}
+
+// Over reports whether the supply is still over budget, which happens when
+// its idle power is.
+func (s SupplyReport) Over() bool {
+	return s.Draw > s.Budget
+}
+
+func (s SupplyReport) String() string {
+	str := fmt.Sprintf("%s: %.1fW of %.1fW", s.Name, s.Draw, s.Budget)
+	if s.Limited() {
+		str += fmt.Sprintf(", limited from %.1fW (%.0f%%)", s.Demand, s.Draw/s.Demand*100)
+	}
+	if s.Over() {
+		str += ", over budget"
+	}
+	return str
+}
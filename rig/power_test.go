@@ -0,0 +1,328 @@
+package rig
+
+import (
+	"bytes"
+	"math"
+	"testing"
+
+	"lyra.codes/blinken/color"
+	"lyra.codes/blinken/dmx"
+)
+
+var profileDRGB = &dmx.FixtureProfile{Name: "DRGB", Channels: []dmx.ProfileChannel{
+	{Role: dmx.Intensity}, {Role: dmx.Red}, {Role: dmx.Green}, {Role: dmx.Blue},
+}}
+
+// repeat returns n copies of a color.
+func repeat(c color.RGBW, n int) []color.RGBW {
+	colors := make([]color.RGBW, n)
+	for i := range colors {
+		colors[i] = c
+	}
+	return colors
+}
+
+func closeTo(a, b float64) bool {
+	return math.Abs(a-b) < 1e-9
+}
+
+func closeToColor(a, b color.RGBW) bool {
+	return closeTo(a.R, b.R) && closeTo(a.G, b.G) && closeTo(a.B, b.B) && closeTo(a.W, b.W)
+}
+
+func TestLimit(t *testing.T) {
+	rgbw := PowerModel{Watts: map[dmx.Role]float64{dmx.Red: 0.1, dmx.Green: 0.1, dmx.Blue: 0.1, dmx.White: 0.2}}
+	rgba := PowerModel{Watts: map[dmx.Role]float64{dmx.Red: 1, dmx.Green: 1, dmx.Blue: 1, dmx.Amber: 2}}
+	idle := rgbw
+	idle.Idle = 0.45
+
+	tests := []struct {
+		name     string
+		limiting Limiting
+		budget   float64
+		model    PowerModel
+		layout   dmx.PixelLayout
+		colors   []color.RGBW
+		want     color.RGBW
+		draw     float64
+	}{
+		{"within budget", Proportional, 3, rgbw, dmx.LayoutRGBW, repeat(color.RGBW{R: 1, W: 1}, 10), color.RGBW{R: 1, W: 1}, 3},
+		{"proportional", Proportional, 1.5, rgbw, dmx.LayoutRGBW, repeat(color.RGBW{R: 1, W: 1}, 10), color.RGBW{R: 0.5, W: 0.5}, 1.5},
+		{"per channel", PerChannel, 1.5, rgbw, dmx.LayoutRGBW, repeat(color.RGBW{R: 1, W: 1}, 10), color.RGBW{R: 0.75, W: 0.375}, 1.5},
+		{"levels over full", Proportional, 1.5, rgbw, dmx.LayoutRGBW, repeat(color.RGBW{R: 2, W: 1}, 10), color.RGBW{R: 0.5, W: 0.5}, 1.5},
+		{"mixed white", Proportional, 0.15, rgbw, dmx.LayoutRGB, repeat(color.RGBW{W: 1}, 1), color.RGBW{R: 0.5, G: 0.5, B: 0.5}, 0.15},
+		{"amber follows red and green", PerChannel, 1.5, rgba, dmx.LayoutRGBA, repeat(color.RGBW{R: 1, G: 1}, 1), color.RGBW{R: 0.25, G: 0.25}, 1.5},
+		{"idle over budget", Proportional, 4, idle, dmx.LayoutRGBW, repeat(color.RGBW{R: 1, W: 1}, 10), color.RGBW{}, 4.5},
+		{"idle over budget per channel", PerChannel, 4, idle, dmx.LayoutRGBW, repeat(color.RGBW{R: 1, W: 1}, 10), color.RGBW{}, 4.5},
+	}
+
+	for _, tt := range tests {
+		l := NewLimiter(tt.limiting)
+		if err := l.AddSupply("psu", tt.budget); err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+
+		report, err := l.Limit([]Load{{Supply: "psu", Model: tt.model, Layout: tt.layout, Colors: tt.colors}})
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		for i, c := range tt.colors {
+			if !closeToColor(c, tt.want) {
+				t.Errorf("%s: color %d is %v, want %v", tt.name, i, c, tt.want)
+				break
+			}
+		}
+		if len(report.Supplies) != 1 {
+			t.Fatalf("%s: report has %d supplies, want 1", tt.name, len(report.Supplies))
+		}
+		if s := report.Supplies[0]; !closeTo(s.Draw, tt.draw) {
+			t.Errorf("%s: draw is %vW, want %vW", tt.name, s.Draw, tt.draw)
+		}
+	}
+}
+
+func TestLimitFixtures(t *testing.T) {
+	drgb := PowerModel{Watts: map[dmx.Role]float64{dmx.Intensity: 10, dmx.Red: 20, dmx.Green: 20, dmx.Blue: 20}}
+	rgb := PowerModel{Watts: map[dmx.Role]float64{dmx.Red: 10, dmx.Green: 10, dmx.Blue: 10}}
+
+	tests := []struct {
+		name     string
+		limiting Limiting
+		budget   float64
+		model    PowerModel
+		profile  *dmx.FixtureProfile
+		params   dmx.Params
+		want     dmx.Params
+		demand   float64
+	}{
+		{
+			name:     "within budget",
+			limiting: Proportional,
+			budget:   30,
+			model:    drgb,
+			profile:  profileDRGB,
+			params:   dmx.Params{dmx.Intensity: 1, dmx.Red: 1},
+			want:     dmx.Params{dmx.Intensity: 1, dmx.Red: 1},
+			demand:   30,
+		},
+		{
+			name:     "proportional by intensity",
+			limiting: Proportional,
+			budget:   15,
+			model:    drgb,
+			profile:  profileDRGB,
+			params:   dmx.Params{dmx.Intensity: 1, dmx.Red: 1},
+			want:     dmx.Params{dmx.Intensity: 0.5, dmx.Red: 1},
+			demand:   30,
+		},
+		{
+			name:     "dimmed intensity draws less",
+			limiting: Proportional,
+			budget:   15,
+			model:    drgb,
+			profile:  profileDRGB,
+			params:   dmx.Params{dmx.Intensity: 0.5, dmx.Red: 1},
+			want:     dmx.Params{dmx.Intensity: 0.5, dmx.Red: 1},
+			demand:   15,
+		},
+		{
+			name:     "proportional by colors",
+			limiting: Proportional,
+			budget:   10,
+			model:    rgb,
+			profile:  dmx.ProfileRGB,
+			params:   dmx.Params{dmx.Red: 1, dmx.Green: 1},
+			want:     dmx.Params{dmx.Red: 0.5, dmx.Green: 0.5, dmx.Blue: 0},
+			demand:   20,
+		},
+		{
+			name:     "per channel",
+			limiting: PerChannel,
+			budget:   15,
+			model:    drgb,
+			profile:  profileDRGB,
+			params:   dmx.Params{dmx.Intensity: 1, dmx.Red: 1},
+			want:     dmx.Params{dmx.Intensity: 0.75, dmx.Red: 0.375, dmx.Green: 0, dmx.Blue: 0},
+			demand:   30,
+		},
+		{
+			name:     "intensity watts without an intensity channel",
+			limiting: Proportional,
+			budget:   10,
+			model:    drgb,
+			profile:  dmx.ProfileRGB,
+			params:   dmx.Params{dmx.Green: 0.5},
+			want:     dmx.Params{dmx.Green: 0.5},
+			demand:   10,
+		},
+	}
+
+	for _, tt := range tests {
+		l := NewLimiter(tt.limiting)
+		if err := l.AddSupply("psu", tt.budget); err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+
+		loads := []FixtureLoad{{Supply: "psu", Model: tt.model, Profile: tt.profile, Params: tt.params}}
+		report, err := l.LimitFixtures(loads)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+
+		got := loads[0].Params
+		if len(got) != len(tt.want) {
+			t.Errorf("%s: params are %v, want %v", tt.name, got, tt.want)
+			continue
+		}
+		for role, v := range tt.want {
+			if g, ok := got[role]; !ok || !closeTo(g, v) {
+				t.Errorf("%s: params are %v, want %v", tt.name, got, tt.want)
+				break
+			}
+		}
+		if s := report.Supplies[0]; !closeTo(s.Demand, tt.demand) {
+			t.Errorf("%s: demand is %vW, want %vW", tt.name, s.Demand, tt.demand)
+		}
+		if s := report.Supplies[0]; s.Draw > tt.budget+1e-9 {
+			t.Errorf("%s: draw is %vW, over the budget of %vW", tt.name, s.Draw, tt.budget)
+		}
+	}
+}
+
+func TestLimiterSupplies(t *testing.T) {
+	l := NewLimiter(Proportional)
+	if err := l.AddSupply("psu", 10); err != nil {
+		t.Fatal(err)
+	}
+	if err := l.AddSupply("psu", 20); err == nil {
+		t.Error("AddSupply added psu twice")
+	}
+	if err := l.AddSupply("negative", -1); err == nil {
+		t.Error("AddSupply accepted a negative budget")
+	}
+
+	colors := []color.RGBW{{R: 1}}
+	if _, err := l.Limit([]Load{{Supply: "other", Layout: dmx.LayoutRGB, Colors: colors}}); err == nil {
+		t.Error("Limit accepted a load on an unknown supply")
+	}
+	if _, err := l.LimitFixtures([]FixtureLoad{{Supply: "other", Profile: dmx.ProfileRGB}}); err == nil {
+		t.Error("LimitFixtures accepted a load on an unknown supply")
+	}
+}
+
+func TestReport(t *testing.T) {
+	model := PowerModel{Watts: map[dmx.Role]float64{dmx.Red: 1, dmx.Green: 1, dmx.Blue: 1}, Idle: 0.45}
+	l := NewLimiter(Proportional)
+	for name, budget := range map[string]float64{"b": 2, "a": 10, "c": 0.4} {
+		if err := l.AddSupply(name, budget); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	report, err := l.Limit([]Load{
+		{Supply: "b", Model: model, Layout: dmx.LayoutRGB, Colors: repeat(color.RGBW{R: 1, G: 1}, 2)},
+		{Supply: "a", Model: model, Layout: dmx.LayoutRGB, Colors: repeat(color.RGBW{R: 1}, 1)},
+		{Supply: "c", Model: model, Layout: dmx.LayoutRGB, Colors: repeat(color.RGBW{}, 1)},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := "a: 1.4W of 10.0W; b: 2.0W of 2.0W, limited from 4.9W (41%); c: 0.5W of 0.4W, over budget"
+	if got := report.String(); got != want {
+		t.Errorf("report is %q, want %q", got, want)
+	}
+	if !report.Limited() {
+		t.Error("report isn't limited")
+	}
+
+	tests := []struct {
+		name    string
+		limited bool
+		over    bool
+		scale   float64
+	}{
+		{"a", false, false, 1},
+		{"b", true, false, 1.1 / 4},
+		{"c", false, true, 0},
+	}
+	for i, tt := range tests {
+		s := report.Supplies[i]
+		if s.Name != tt.name {
+			t.Errorf("supply %d is %s, want %s", i, s.Name, tt.name)
+			continue
+		}
+		if s.Limited() != tt.limited || s.Over() != tt.over {
+			t.Errorf("%s: limited %v and over %v, want %v and %v", tt.name, s.Limited(), s.Over(), tt.limited, tt.over)
+		}
+		if scale := s.Scale[dmx.Red]; !closeTo(scale, tt.scale) {
+			t.Errorf("%s: red is scaled by %v, want %v", tt.name, scale, tt.scale)
+		}
+	}
+
+	if report, _ := l.Limit([]Load{{Supply: "a", Model: model, Layout: dmx.LayoutRGB, Colors: repeat(color.RGBW{R: 1}, 1)}}); report.Limited() {
+		t.Errorf("report %s is limited", report)
+	}
+}
+
+func TestRenderLimiter(t *testing.T) {
+	p := NewPatch()
+	a, err := p.Add("a", 1, 1, profileDRGB)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := p.Add("b", 1, 5, profileDRGB); err != nil {
+		t.Fatal(err)
+	}
+	a.Power = &PowerModel{Watts: map[dmx.Role]float64{dmx.Intensity: 10, dmx.Red: 20}}
+	a.Supply = "psu"
+
+	p.Limiter = NewLimiter(Proportional)
+	if err := p.Limiter.AddSupply("psu", 15); err != nil {
+		t.Fatal(err)
+	}
+
+	state := State{"a": {dmx.Intensity: 1, dmx.Red: 1}, "b": {dmx.Intensity: 1, dmx.Red: 1}}
+	frame, err := p.Render(state)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := (dmx.Universe{128, 255, 0, 0, 255, 255, 0, 0}); !bytes.Equal(frame[1][:8], want) {
+		t.Errorf("universe starts %v, want %v", frame[1][:8], want)
+	}
+	if s := p.PowerReport().Supplies; len(s) != 1 || !closeTo(s[0].Demand, 30) || !closeTo(s[0].Draw, 15) {
+		t.Errorf("power report is %v", p.PowerReport())
+	}
+
+	a.Supply = "other"
+	if _, err := p.Render(state); err == nil {
+		t.Error("Render limited a fixture on an unknown supply")
+	}
+}
+
+func TestPixelStripLimiter(t *testing.T) {
+	s, err := NewPixelStrip(2, dmx.LayoutRGB, 1, WholePixels)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.Limiter = NewLimiter(Proportional)
+	if err := s.Limiter.AddSupply("psu", 0.75); err != nil {
+		t.Fatal(err)
+	}
+	s.Supply = "psu"
+	s.Power = PowerModel{Watts: map[dmx.Role]float64{dmx.Red: 0.25, dmx.Green: 0.25, dmx.Blue: 0.25}}
+
+	colors := repeat(color.RGBW{W: 1}, 2)
+	if err := s.Set(colors); err != nil {
+		t.Fatal(err)
+	}
+	if want := (dmx.Universe{128, 128, 128, 128, 128, 128}); !bytes.Equal(s.Frame()[1], want) {
+		t.Errorf("limited strip is %v, want %v", s.Frame()[1], want)
+	}
+	if colors[0] != (color.RGBW{W: 1}) {
+		t.Error("Set changed the caller's colors")
+	}
+	if r := s.PowerReport(); !r.Limited() || !closeTo(r.Supplies[0].Draw, 0.75) {
+		t.Errorf("power report is %v", r)
+	}
+}
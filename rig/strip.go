@@ -0,0 +1,207 @@
+package rig
+
+import (
+	"fmt"
+
+	"lyra.codes/blinken/artnet"
+	"lyra.codes/blinken/color"
+	"lyra.codes/blinken/dmx"
+)
+
+// maxAddress is the highest Art-Net port-address.
+const maxAddress artnet.Address = 0x7FFF
+
+// Packing is how a PixelStrip places its pixels into universes.
+type Packing uint8
+
+const (
+	// WholePixels starts a new universe rather than split a pixel between
+	// two, leaving the end of each universe unused if pixels don't fit
+	// exactly.
+	WholePixels Packing = iota
+
+	// PackPixels uses every channel of each universe, so a pixel may start
+	// in one universe and end in the next.
+	PackPixels
+)
+
+func (p Packing) String() string {
+	switch p {
+	case WholePixels:
+		return "whole pixels"
+	case PackPixels:
+		return "pack pixels"
+	default:
+		return fmt.Sprintf("Packing(%d)", uint8(p))
+	}
+}
+
+// PixelStrip is a strip of pixels spanning consecutive universes.
+type PixelStrip struct {
+	Count   int
+	Layout  dmx.PixelLayout
+	Start   artnet.Address
+	Packing Packing
+
+	// Curves are applied to colors set on the strip.
+	Curves dmx.Curves
+
+	// Dither, if set, dithers the strip's pixels.
+	Dither *dmx.Dither
+
+	// Masters, if set, dim the strip by the grand master and the masters
+	// of its Groups.
+	Masters *Masters
+	Groups  []string
+
+	// Limiter, if set, limits the power the strip draws from Supply, with
+	// Power the model of each pixel. The strip is limited on its own, so
+	// its supply shouldn't power anything else.
+	Limiter *Limiter
+	Supply  string
+	Power   PowerModel
+	report  Report
+
+	// channels are the channels of every pixel, which are split into
+	// universes holding sizes channels each.
+	channels  []dmx.Channel
+	sizes     []int
+	universes []dmx.Universe
+	dimmed    []color.RGBW
+}
+
+// NewPixelStrip creates a strip of count pixels, starting at the first
+// channel of the start universe.
+func NewPixelStrip(count int, layout dmx.PixelLayout, start artnet.Address, packing Packing) (*PixelStrip, error) {
+	footprint := layout.Footprint()
+	if footprint == 0 || footprint > dmx.UniverseSize {
+		return nil, fmt.Errorf("%s pixels have %d channels", layout.Name, footprint)
+	}
+	if count < 0 {
+		return nil, fmt.Errorf("strip of %d pixels", count)
+	}
+
+	s := &PixelStrip{
+		Count:   count,
+		Layout:  layout,
+		Start:   start,
+		Packing: packing,
+	}
+
+	var sizes []int
+	switch packing {
+	case WholePixels:
+		per := dmx.UniverseSize / footprint
+		for n := count; n > 0; n -= per {
+			if n < per {
+				sizes = append(sizes, n*footprint)
+			} else {
+				sizes = append(sizes, per*footprint)
+			}
+		}
+	case PackPixels:
+		for n := count * footprint; n > 0; n -= dmx.UniverseSize {
+			if n < dmx.UniverseSize {
+				sizes = append(sizes, n)
+			} else {
+				sizes = append(sizes, dmx.UniverseSize)
+			}
+		}
+	default:
+		return nil, fmt.Errorf("unknown packing %s", packing)
+	}
+
+	if len(sizes) > 0 && int(start)+len(sizes)-1 > int(maxAddress) {
+		return nil, fmt.Errorf("strip of %d pixels from %s needs %d universes, past %s", count, start, len(sizes), maxAddress)
+	}
+
+	s.channels = make([]dmx.Channel, count*footprint)
+	s.sizes = sizes
+
+	// Art-Net sends an even number of channels.
+	s.universes = make([]dmx.Universe, len(sizes))
+	for i, size := range sizes {
+		s.universes[i] = make(dmx.Universe, size+size%2)
+	}
+
+	return s, nil
+}
+
+// Universes returns the port-addresses of the universes the strip spans.
+func (s *PixelStrip) Universes() []artnet.Address {
+	addrs := make([]artnet.Address, len(s.universes))
+	for i := range addrs {
+		addrs[i] = s.Start + artnet.Address(i)
+	}
+	return addrs
+}
+
+// Locate returns the universe and first channel, from 1, of a pixel.
+func (s *PixelStrip) Locate(index int) (artnet.Address, int) {
+	u, ch := s.locate(index)
+	return s.Start + artnet.Address(u), ch + 1
+}
+
+// locate returns the index of a pixel's universe and its offset within it.
+func (s *PixelStrip) locate(index int) (int, int) {
+	footprint := s.Layout.Footprint()
+	if s.Packing == WholePixels {
+		per := dmx.UniverseSize / footprint
+		return index / per, index % per * footprint
+	}
+
+	offset := index * footprint
+	return offset / dmx.UniverseSize, offset % dmx.UniverseSize
+}
+
+// Set writes colors into the strip's pixels, starting at the first. The
+// masters dim the colors, then the limiter limits their power.
+func (s *PixelStrip) Set(colors []color.RGBW) error {
+	if len(colors) > s.Count {
+		return fmt.Errorf("%d colors for a strip of %d pixels", len(colors), s.Count)
+	}
+
+	level := s.Masters.Level(s.Groups...)
+	if level != 1 || s.Limiter != nil {
+		s.dimmed = append(s.dimmed[:0], colors...)
+		colors = s.dimmed
+	}
+	if level != 1 {
+		for i, c := range colors {
+			colors[i] = color.RGBW{R: c.R * level, G: c.G * level, B: c.B * level, W: c.W * level}
+		}
+	}
+	if s.Limiter != nil {
+		report, err := s.Limiter.Limit([]Load{{Supply: s.Supply, Model: s.Power, Layout: s.Layout, Colors: colors}})
+		if err != nil {
+			return err
+		}
+		s.report = report
+	}
+
+	pixels := dmx.Pixels{Layout: s.Layout, Channels: s.channels, Curves: s.Curves, Dither: s.Dither}
+	pixels.Spread(0, colors)
+
+	offset := 0
+	for i, size := range s.sizes {
+		copy(s.universes[i], s.channels[offset:offset+size])
+		offset += size
+	}
+
+	return nil
+}
+
+// PowerReport returns the limiting applied to the last colors set.
+func (s *PixelStrip) PowerReport() Report {
+	return s.report
+}
+
+// Frame returns the strip's universes. The frame shares the strip's
+// buffers, which Set overwrites.
+func (s *PixelStrip) Frame() Frame {
+	frame := make(Frame, len(s.universes))
+	for i, u := range s.universes {
+		frame[s.Start+artnet.Address(i)] = u
+	}
+	return frame
+}
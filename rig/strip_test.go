@@ -0,0 +1,160 @@
+package rig
+
+import (
+	"testing"
+
+	"lyra.codes/blinken/artnet"
+	"lyra.codes/blinken/color"
+	"lyra.codes/blinken/dmx"
+)
+
+func TestNewPixelStrip(t *testing.T) {
+	tests := []struct {
+		name    string
+		count   int
+		layout  dmx.PixelLayout
+		packing Packing
+		lengths []int
+	}{
+		{"600 RGBW whole pixels", 600, dmx.LayoutRGBW, WholePixels, []int{512, 512, 512, 512, 352}},
+		{"600 RGBW packed", 600, dmx.LayoutRGBW, PackPixels, []int{512, 512, 512, 512, 352}},
+		{"171 RGB whole pixels", 171, dmx.LayoutRGB, WholePixels, []int{510, 4}},
+		{"171 RGB packed", 171, dmx.LayoutRGB, PackPixels, []int{512, 2}},
+		{"3 RGB pads to even", 3, dmx.LayoutRGB, WholePixels, []int{10}},
+		{"100 RGB16", 100, dmx.PixelLayout{Name: "RGB16", Order: dmx.LayoutRGB.Order, Wide: true}, PackPixels, []int{512, 88}},
+		{"no pixels", 0, dmx.LayoutRGB, WholePixels, nil},
+	}
+
+	for _, tt := range tests {
+		s, err := NewPixelStrip(tt.count, tt.layout, 3, tt.packing)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+
+		frame := s.Frame()
+		if len(frame) != len(tt.lengths) {
+			t.Errorf("%s: %d universes, want %d", tt.name, len(frame), len(tt.lengths))
+			continue
+		}
+		for i, want := range tt.lengths {
+			addr := artnet.Address(3 + i)
+			if got := len(frame[addr]); got != want {
+				t.Errorf("%s: universe %s has %d channels, want %d", tt.name, addr, got, want)
+			}
+		}
+	}
+
+	if _, err := NewPixelStrip(600, dmx.LayoutRGBW, maxAddress-3, WholePixels); err == nil {
+		t.Error("NewPixelStrip ran past the last port-address")
+	}
+	if _, err := NewPixelStrip(-1, dmx.LayoutRGB, 0, WholePixels); err == nil {
+		t.Error("NewPixelStrip accepted -1 pixels")
+	}
+}
+
+func TestPixelStripLocate(t *testing.T) {
+	whole, err := NewPixelStrip(600, dmx.LayoutRGBW, 1, WholePixels)
+	if err != nil {
+		t.Fatal(err)
+	}
+	packed, err := NewPixelStrip(200, dmx.LayoutRGB, 1, PackPixels)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		strip    *PixelStrip
+		index    int
+		universe artnet.Address
+		channel  int
+	}{
+		{whole, 0, 1, 1},
+		{whole, 127, 1, 509},
+		{whole, 128, 2, 1},
+		{whole, 599, 5, 349},
+		{packed, 169, 1, 508},
+		{packed, 170, 1, 511},
+		{packed, 171, 2, 2},
+	}
+
+	for _, tt := range tests {
+		u, ch := tt.strip.Locate(tt.index)
+		if u != tt.universe || ch != tt.channel {
+			t.Errorf("%s pixel %d is at %s/%d, want %s/%d", tt.strip.Packing, tt.index, u, ch, tt.universe, tt.channel)
+		}
+	}
+}
+
+func TestPixelStripSet(t *testing.T) {
+	s, err := NewPixelStrip(171, dmx.LayoutRGB, 1, PackPixels)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	colors := make([]color.RGBW, 171)
+	colors[170] = color.RGBW{R: 1, G: 0.5, B: 1}
+	if err := s.Set(colors); err != nil {
+		t.Fatal(err)
+	}
+
+	// Pixel 170 starts at the 511th channel of the first universe, and
+	// its blue is the first channel of the second.
+	frame := s.Frame()
+	if got := frame[1][510:]; got[0] != 255 || got[1] != 128 {
+		t.Errorf("end of the first universe is %v, want [255 128]", got)
+	}
+	if got := frame[2]; got[0] != 255 || got[1] != 0 {
+		t.Errorf("second universe is %v, want [255 0]", got)
+	}
+
+	if err := s.Set(make([]color.RGBW, 172)); err == nil {
+		t.Error("Set accepted more colors than pixels")
+	}
+}
+
+func TestPixelStripSetWhole(t *testing.T) {
+	s, err := NewPixelStrip(600, dmx.LayoutRGBW, 1, WholePixels)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	colors := make([]color.RGBW, 600)
+	for i := range colors {
+		colors[i] = color.RGBW{W: 1}
+	}
+	if err := s.Set(colors); err != nil {
+		t.Fatal(err)
+	}
+
+	frame := s.Frame()
+	for _, addr := range frame.Universes() {
+		u := frame[addr]
+		for ch := 3; ch < len(u); ch += 4 {
+			if u[ch] != 255 {
+				t.Fatalf("universe %s channel %d is %d, want 255", addr, ch+1, u[ch])
+			}
+		}
+	}
+}
+
+func TestPixelStripMasters(t *testing.T) {
+	s, err := NewPixelStrip(2, dmx.LayoutRGB, 1, WholePixels)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.Masters = NewMasters()
+	s.Groups = []string{"strips"}
+	s.Masters.SetGrand(0.5)
+	s.Masters.SetGroup("strips", 0.5)
+
+	colors := []color.RGBW{{R: 1}, {G: 1}}
+	if err := s.Set(colors); err != nil {
+		t.Fatal(err)
+	}
+	if u := s.Frame()[1]; u[0] != 64 || u[4] != 64 {
+		t.Errorf("dimmed strip is %v", u)
+	}
+	if colors[0].R != 1 {
+		t.Error("Set changed the caller's colors")
+	}
+}
@@ -0,0 +1,100 @@
+package sacn
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+
+	"lyra.codes/blinken/artnet/wire"
+	"lyra.codes/blinken/dmx"
+)
+
+const (
+	// dataHeaderLength is the length of a data packet before its slots.
+	dataHeaderLength = 126
+
+	framingLayerOffset = 38
+	dmpLayerOffset     = 115
+)
+
+// Data is an E1.31 data packet, carrying the slots of one universe.
+type Data struct {
+	RootLayer
+	SourceName  string
+	Priority    uint8
+	SyncAddress Universe
+	Sequence    uint8
+	Options     Options
+	Universe    Universe
+	StartCode   uint8
+	Data        dmx.Universe
+}
+
+// NewData creates a new data packet for a universe.
+func NewData(cid CID, source string, universe Universe, seq uint8, channels dmx.Universe) *Data {
+	return &Data{
+		RootLayer:  RootLayer{Vector: VectorRootData, CID: cid},
+		SourceName: source,
+		Priority:   DefaultPriority,
+		Sequence:   seq,
+		Universe:   universe,
+		Data:       channels,
+	}
+}
+
+func (p *Data) Read(r wire.Reader) error {
+	parser := wire.Parse(r)
+	p.RootLayer.read(parser)
+
+	parser.Int16("FlagsLength", binary.BigEndian)
+	if v := parser.Int32("Vector", binary.BigEndian); parser.Err() == nil && v != VectorDataPacket {
+		parser.Fail("Vector", fmt.Errorf("unexpected framing vector 0x%08x", v))
+	}
+	p.SourceName = parser.String("SourceName", 64)
+	p.Priority = parser.Int8("Priority")
+	p.SyncAddress = Universe(parser.Int16("SyncAddress", binary.BigEndian))
+	p.Sequence = parser.Int8("Sequence")
+	p.Options = Options(parser.Int8("Options"))
+	p.Universe = Universe(parser.Int16("Universe", binary.BigEndian))
+
+	parser.Int16("FlagsLength", binary.BigEndian)
+	parser.Int8("Vector")
+	parser.Int8("AddressType")
+	parser.Int16("FirstAddress", binary.BigEndian)
+	parser.Int16("AddressIncrement", binary.BigEndian)
+	count := parser.Int16("Count", binary.BigEndian)
+	p.StartCode = parser.Int8("StartCode")
+	if parser.Err() != nil {
+		return parser.Err()
+	}
+
+	if count < 1 || count > 513 {
+		return &wire.FieldError{Field: "Count", Err: fmt.Errorf("invalid property count %d", count)}
+	}
+
+	p.Data = dmx.Universe(parser.Bytes("Data", int(count)-1))
+	return parser.Err()
+}
+
+func (p *Data) Write(w io.Writer) error {
+	total := dataHeaderLength + len(p.Data)
+
+	return p.RootLayer.write(wire.Build(w), total).
+		Int16("FlagsLength", flagsLength(total-framingLayerOffset), binary.BigEndian).
+		Int32("Vector", VectorDataPacket, binary.BigEndian).
+		String("SourceName", p.SourceName, 64).
+		Int8("Priority", p.Priority).
+		Int16("SyncAddress", uint16(p.SyncAddress), binary.BigEndian).
+		Int8("Sequence", p.Sequence).
+		Int8("Options", uint8(p.Options)).
+		Int16("Universe", uint16(p.Universe), binary.BigEndian).
+		Int16("FlagsLength", flagsLength(total-dmpLayerOffset), binary.BigEndian).
+		Int8("Vector", VectorDMPSetProperty).
+		Int8("AddressType", dmpAddressAndDataType).
+		Int16("FirstAddress", 0, binary.BigEndian).
+		Int16("AddressIncrement", 1, binary.BigEndian).
+		Int16("Count", uint16(len(p.Data)+1), binary.BigEndian).
+		Int8("StartCode", p.StartCode).
+		Bytes("Data", []byte(p.Data)).
+		Err()
+}
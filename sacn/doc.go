@@ -0,0 +1,2 @@
+// Package sacn implements an ANSI E1.31 (Streaming ACN) source and receiver.
+package sacn
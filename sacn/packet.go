@@ -0,0 +1,12 @@
+package sacn
+
+import (
+	"io"
+
+	"lyra.codes/blinken/artnet/wire"
+)
+
+type Packet interface {
+	Read(r wire.Reader) error
+	Write(w io.Writer) error
+}
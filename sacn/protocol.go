@@ -0,0 +1,139 @@
+package sacn
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/binary"
+	"fmt"
+	"net"
+
+	"lyra.codes/blinken/artnet/wire"
+)
+
+// Port is the standard UDP port in the E1.31 specification.
+const Port = 5568
+
+// Identifier is the ACN packet identifier that begins every E1.31 datagram.
+var Identifier = []byte("ASC-E1.17\x00\x00\x00")
+
+const (
+	preambleSize  = 0x0010
+	postambleSize = 0x0000
+
+	// flags is the value of the high nibble of every PDU's flags & length field.
+	flags = 0x7000
+)
+
+// Vectors identify the contents of each layer of an E1.31 packet.
+const (
+	VectorRootData     uint32 = 0x00000004
+	VectorRootExtended uint32 = 0x00000008
+
+	VectorDataPacket      uint32 = 0x00000002
+	VectorExtendedSync    uint32 = 0x00000001
+	VectorDMPSetProperty  uint8  = 0x02
+	dmpAddressAndDataType uint8  = 0xA1
+)
+
+// Priorities that may be given to E1.31 data.
+const (
+	MinPriority     uint8 = 0
+	DefaultPriority uint8 = 100
+	MaxPriority     uint8 = 200
+)
+
+// Universe is an E1.31 universe number, from 1 to 63999.
+type Universe uint16
+
+const (
+	MinUniverse Universe = 1
+	MaxUniverse Universe = 63999
+)
+
+// Valid reports whether u is a universe that may carry data.
+func (u Universe) Valid() bool {
+	return u >= MinUniverse && u <= MaxUniverse
+}
+
+// Multicast returns the multicast group address of the universe.
+func (u Universe) Multicast() *net.UDPAddr {
+	return &net.UDPAddr{
+		IP:   net.IPv4(239, 255, byte(u>>8), byte(u)),
+		Port: Port,
+	}
+}
+
+// CID is the component identifier (a UUID) of an E1.31 source.
+type CID [16]byte
+
+// NewCID generates a random component identifier.
+func NewCID() CID {
+	var c CID
+	if _, err := rand.Read(c[:]); err != nil {
+		panic(err)
+	}
+
+	c[6] = (c[6] & 0x0F) | 0x40
+	c[8] = (c[8] & 0x3F) | 0x80
+	return c
+}
+
+func (c CID) String() string {
+	return fmt.Sprintf("%x-%x-%x-%x-%x", c[0:4], c[4:6], c[6:8], c[8:10], c[10:16])
+}
+
+// Options are the option flags of an E1.31 data packet.
+type Options uint8
+
+const (
+	OptionPreview    Options = 0x80
+	OptionTerminated Options = 0x40
+	OptionForceSync  Options = 0x20
+)
+
+func (f Options) Enabled(v Options) bool {
+	return (v & f) != 0
+}
+
+func (f Options) Set(v *Options) {
+	*v |= f
+}
+
+// RootLayer is the ACN root layer shared by all E1.31 packets.
+type RootLayer struct {
+	Vector uint32
+	CID    CID
+}
+
+func (l *RootLayer) read(parser *wire.Parser) {
+	parser.Int16("PreambleSize", binary.BigEndian)
+	parser.Int16("PostambleSize", binary.BigEndian)
+	if id := parser.Bytes("Identifier", len(Identifier)); id != nil && !bytes.Equal(id, Identifier) {
+		parser.Fail("Identifier", fmt.Errorf("received invalid identifier %q", id))
+	}
+	parser.Int16("FlagsLength", binary.BigEndian)
+	l.Vector = parser.Int32("Vector", binary.BigEndian)
+	copy(l.CID[:], parser.Bytes("CID", len(l.CID)))
+}
+
+func (l *RootLayer) write(builder *wire.Builder, total int) *wire.Builder {
+	return builder.
+		Int16("PreambleSize", preambleSize, binary.BigEndian).
+		Int16("PostambleSize", postambleSize, binary.BigEndian).
+		Bytes("Identifier", Identifier).
+		Int16("FlagsLength", flagsLength(total-16), binary.BigEndian).
+		Int32("Vector", l.Vector, binary.BigEndian).
+		Bytes("CID", l.CID[:])
+}
+
+func flagsLength(n int) uint16 {
+	return uint16(flags | (n & 0x0FFF))
+}
+
+// InSequence reports whether a packet with sequence number next should be
+// accepted after one with sequence number last, following the out-of-order
+// rule in E1.31 section 6.7.2.
+func InSequence(last, next uint8) bool {
+	diff := int8(next - last)
+	return diff > 0 || diff <= -20
+}
@@ -0,0 +1,95 @@
+package sacn
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+
+	"lyra.codes/blinken/dmx"
+)
+
+func TestInSequence(t *testing.T) {
+	tests := []struct {
+		last, next uint8
+		want       bool
+	}{
+		{1, 2, true},
+		{1, 1, false},
+		{10, 9, false},
+		{10, 0, false},
+		{30, 11, false},
+		{30, 10, true},
+		{30, 12, false},
+		{255, 0, true},
+		{250, 3, true},
+		{3, 250, false},
+	}
+
+	for _, tt := range tests {
+		if got := InSequence(tt.last, tt.next); got != tt.want {
+			t.Errorf("InSequence(%d, %d) = %v, want %v", tt.last, tt.next, got, tt.want)
+		}
+	}
+}
+
+func TestDataRoundTrip(t *testing.T) {
+	cid := CID{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16}
+	data := make(dmx.Universe, 512)
+	for i := range data {
+		data[i] = dmx.Channel(i)
+	}
+
+	p := NewData(cid, "test source", 7, 42, data)
+	p.Priority = 150
+	p.SyncAddress = 9
+	OptionForceSync.Set(&p.Options)
+
+	buf := &bytes.Buffer{}
+	if err := p.Write(buf); err != nil {
+		t.Fatal(err)
+	}
+	if buf.Len() != dataHeaderLength+len(data) {
+		t.Errorf("encoded %d bytes, want %d", buf.Len(), dataHeaderLength+len(data))
+	}
+
+	got, err := decode(buf.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, p) {
+		t.Errorf("decoded %+v, want %+v", got, p)
+	}
+}
+
+func TestSyncRoundTrip(t *testing.T) {
+	p := NewSync(CID{0xAA}, 3, 9)
+
+	buf := &bytes.Buffer{}
+	if err := p.Write(buf); err != nil {
+		t.Fatal(err)
+	}
+	if buf.Len() != syncLength {
+		t.Errorf("encoded %d bytes, want %d", buf.Len(), syncLength)
+	}
+
+	got, err := decode(buf.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, p) {
+		t.Errorf("decoded %+v, want %+v", got, p)
+	}
+}
+
+func TestDecodeShort(t *testing.T) {
+	buf := &bytes.Buffer{}
+	if err := NewSync(CID{}, 1, 1).Write(buf); err != nil {
+		t.Fatal(err)
+	}
+
+	for n := 0; n < buf.Len(); n++ {
+		if _, err := decode(buf.Bytes()[:n]); err == nil {
+			t.Errorf("decoded a sync packet cut to %d bytes", n)
+		}
+	}
+}
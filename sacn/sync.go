@@ -0,0 +1,53 @@
+package sacn
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+
+	"lyra.codes/blinken/artnet/wire"
+)
+
+const syncLength = 49
+
+// Sync is an E1.31 synchronization packet, which tells receivers to output
+// the data they have buffered for universes with a matching SyncAddress.
+type Sync struct {
+	RootLayer
+	Sequence    uint8
+	SyncAddress Universe
+}
+
+// NewSync creates a new synchronization packet.
+func NewSync(cid CID, seq uint8, address Universe) *Sync {
+	return &Sync{
+		RootLayer:   RootLayer{Vector: VectorRootExtended, CID: cid},
+		Sequence:    seq,
+		SyncAddress: address,
+	}
+}
+
+func (p *Sync) Read(r wire.Reader) error {
+	parser := wire.Parse(r)
+	p.RootLayer.read(parser)
+
+	parser.Int16("FlagsLength", binary.BigEndian)
+	if v := parser.Int32("Vector", binary.BigEndian); parser.Err() == nil && v != VectorExtendedSync {
+		parser.Fail("Vector", fmt.Errorf("unexpected framing vector 0x%08x", v))
+	}
+	p.Sequence = parser.Int8("Sequence")
+	p.SyncAddress = Universe(parser.Int16("SyncAddress", binary.BigEndian))
+	parser.Skip("Reserved", 2)
+
+	return parser.Err()
+}
+
+func (p *Sync) Write(w io.Writer) error {
+	return p.RootLayer.write(wire.Build(w), syncLength).
+		Int16("FlagsLength", flagsLength(syncLength-framingLayerOffset), binary.BigEndian).
+		Int32("Vector", VectorExtendedSync, binary.BigEndian).
+		Int8("Sequence", p.Sequence).
+		Int16("SyncAddress", uint16(p.SyncAddress), binary.BigEndian).
+		Int16("Reserved", 0, binary.BigEndian).
+		Err()
+}
@@ -0,0 +1,189 @@
+package sacn
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+)
+
+type Transport interface {
+	Send(to *net.UDPAddr, packet Packet) error
+
+	// Join starts receiving packets sent to the multicast group of a universe.
+	Join(universe Universe) error
+	// Leave stops receiving packets for a universe.
+	Leave(universe Universe) error
+
+	Packets() <-chan Message
+}
+
+// Message is a packet received by a Transport.
+type Message struct {
+	From   *net.UDPAddr
+	Packet Packet
+}
+
+// Listen creates a Transport which joins multicast groups on the given
+// interface, or on the system default interface if ifi is nil.
+func Listen(ctx context.Context, ifi *net.Interface) (Transport, error) {
+	conn, err := net.ListenUDP("udp4", &net.UDPAddr{})
+	if err != nil {
+		return nil, err
+	}
+
+	t := &networkTransport{
+		ctx:  ctx,
+		ifi:  ifi,
+		conn: conn,
+
+		groups:  make(map[Universe]*net.UDPConn),
+		packets: make(chan Message, 64),
+	}
+
+	go func() {
+		<-ctx.Done()
+		t.close()
+	}()
+
+	return t, nil
+}
+
+type networkTransport struct {
+	ctx  context.Context
+	ifi  *net.Interface
+	conn *net.UDPConn
+
+	mu      sync.Mutex
+	groups  map[Universe]*net.UDPConn
+	packets chan Message
+	wg      sync.WaitGroup
+	closed  bool
+}
+
+func (t *networkTransport) Send(to *net.UDPAddr, packet Packet) error {
+	buf := bytes.Buffer{}
+	if err := packet.Write(&buf); err != nil {
+		return err
+	}
+
+	if _, err := t.conn.WriteToUDP(buf.Bytes(), to); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (t *networkTransport) Join(universe Universe) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.closed {
+		return fmt.Errorf("transport is closed")
+	}
+	if _, ok := t.groups[universe]; ok {
+		return nil
+	}
+
+	conn, err := net.ListenMulticastUDP("udp4", t.ifi, universe.Multicast())
+	if err != nil {
+		return err
+	}
+
+	t.groups[universe] = conn
+	t.wg.Add(1)
+	go t.receive(conn)
+
+	return nil
+}
+
+func (t *networkTransport) Leave(universe Universe) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	conn, ok := t.groups[universe]
+	if !ok {
+		return nil
+	}
+
+	delete(t.groups, universe)
+	return conn.Close()
+}
+
+func (t *networkTransport) Packets() <-chan Message {
+	return t.packets
+}
+
+func (t *networkTransport) close() {
+	t.mu.Lock()
+	t.closed = true
+	for universe, conn := range t.groups {
+		conn.Close()
+		delete(t.groups, universe)
+	}
+	t.mu.Unlock()
+
+	t.conn.Close()
+	t.wg.Wait()
+	close(t.packets)
+}
+
+func (t *networkTransport) receive(conn *net.UDPConn) {
+	defer t.wg.Done()
+
+	buf := make([]byte, 1144)
+	for {
+		n, from, err := conn.ReadFromUDP(buf)
+		if err != nil {
+			if errors.Is(err, net.ErrClosed) {
+				return
+			}
+			if nerr, ok := err.(net.Error); ok && nerr.Timeout() {
+				continue
+			}
+
+			return
+		}
+
+		p, err := decode(buf[:n])
+		if err != nil {
+			fmt.Printf("invalid E1.31 packet from %s: %v\n", from, err)
+			continue
+		}
+
+		select {
+		case t.packets <- Message{From: from, Packet: p}:
+		case <-t.ctx.Done():
+			return
+		}
+	}
+}
+
+// decode parses an E1.31 data or synchronization packet.
+func decode(body []byte) (Packet, error) {
+	if len(body) < framingLayerOffset+6 {
+		return nil, fmt.Errorf("packet too short (%d bytes)", len(body))
+	}
+
+	root := binary.BigEndian.Uint32(body[18:22])
+	framing := binary.BigEndian.Uint32(body[40:44])
+
+	var p Packet
+	switch {
+	case root == VectorRootData && framing == VectorDataPacket:
+		p = &Data{}
+	case root == VectorRootExtended && framing == VectorExtendedSync:
+		p = &Sync{}
+	default:
+		return nil, fmt.Errorf("unsupported vectors 0x%08x/0x%08x", root, framing)
+	}
+
+	if err := p.Read(bytes.NewBuffer(body)); err != nil {
+		return nil, err
+	}
+
+	return p, nil
+}